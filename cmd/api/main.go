@@ -4,25 +4,61 @@ import (
 	"bookcabin-test/internal/core/services"
 	"bookcabin-test/internal/handlers"
 	"bookcabin-test/internal/platform/providers"
+	"bookcabin-test/internal/platform/redis"
+	"bookcabin-test/internal/platform/stubserver"
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
 
+const defaultProviderBaseURL = "http://localhost:8081"
+
 func main() {
-	aggregator := services.NewAggregator([]providers.ProviderInterface{
-		&providers.GarudaProvider{},
-		&providers.LionAirProvider{},
-		&providers.BatikAirProvider{},
-		&providers.AirAsiaProvider{},
-	})
+	baseURL := getEnv("PROVIDER_BASE_URL", defaultProviderBaseURL)
+	if os.Getenv("EMBEDDED_STUB") == "true" {
+		baseURL = startEmbeddedStub()
+	}
+
+	retryPolicy := providers.DefaultRetryPolicy()
+	flakyRetryPolicy := providers.DefaultRetryPolicy()
+	flakyRetryPolicy.AttemptTimeout = 200 * time.Millisecond
+
+	breakerConfig := providers.DefaultBreakerConfig()
+
+	cache, closeCache := newCache()
+	defer closeCache()
+
+	aggregator := services.NewAggregatorWithCache([]providers.ProviderInterface{
+		providers.WithCircuitBreaker(providers.WithRetry(providers.NewGarudaProvider(providerConfig("GARUDA", baseURL)), retryPolicy), breakerConfig),
+		providers.WithCircuitBreaker(providers.WithRetry(providers.NewLionAirProvider(providerConfig("LION", baseURL)), retryPolicy), breakerConfig),
+		providers.WithCircuitBreaker(providers.WithRetry(providers.NewBatikAirProvider(providerConfig("BATIK", baseURL)), retryPolicy), breakerConfig),
+		providers.WithCircuitBreaker(providers.WithRetry(providers.NewAirAsiaProvider(providerConfig("AIRASIA", baseURL)), flakyRetryPolicy), breakerConfig),
+	}, cache)
+
+	aggregator.Hedging.Enabled = os.Getenv("HEDGE_REQUESTS") == "true"
+	for i, prefix := range []string{"GARUDA", "LION", "BATIK", "AIRASIA"} {
+		if ttl := envDuration(prefix+"_CACHE_TTL", 0); ttl > 0 {
+			aggregator.CacheTTLs[aggregator.Providers[i].Name()] = ttl
+		}
+	}
+
+	prewarmCtx, stopPrewarm := context.WithCancel(context.Background())
+	defer stopPrewarm()
+	prewarmer := newPrewarmer(aggregator)
+	if prewarmer != nil {
+		prewarmer.Start(prewarmCtx)
+	}
 
 	searchHandler := handlers.NewSearchHandlers(aggregator)
+	adminHandler := handlers.NewAdminHandlers(aggregator, prewarmer)
 	srv := &http.Server{
 		Addr: ":8080",
 		// Daftarkan handler menggunakan ServeMux default
@@ -34,6 +70,11 @@ func main() {
 	}
 
 	http.HandleFunc("/v1/search", searchHandler.SearchFlight)
+	http.HandleFunc("/v1/search/multi-city", searchHandler.SearchMultiCity)
+	http.HandleFunc("/v1/search/flexible", searchHandler.SearchFlexible)
+	http.HandleFunc("/admin/circuit-breakers", adminHandler.CircuitBreakers)
+	http.HandleFunc("/admin/cache", adminHandler.CacheStats)
+	http.HandleFunc("/admin/prewarm", adminHandler.Prewarm)
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
@@ -57,3 +98,153 @@ func main() {
 
 	log.Println("Server exited properly.")
 }
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// providerConfig membaca konfigurasi per maskapai dari env, mis. GARUDA_BASE_URL,
+// GARUDA_API_KEY dan GARUDA_TIMEOUT.
+func providerConfig(prefix, baseURL string) providers.HTTPConfig {
+	return providers.HTTPConfig{
+		BaseURL:    getEnv(prefix+"_BASE_URL", baseURL),
+		AuthHeader: os.Getenv(prefix + "_AUTH_HEADER"),
+		AuthToken:  os.Getenv(prefix + "_API_KEY"),
+		Timeout:    envDuration(prefix+"_TIMEOUT", providers.DefaultHTTPTimeout),
+	}
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("invalid %s %q: %v", key, v, err)
+		return fallback
+	}
+	return d
+}
+
+// newCache memilih backend cache dari env CACHE_BACKEND: "memory" (default) atau "redis".
+// Backend redis membaca REDIS_ADDR, REDIS_PASSWORD dan REDIS_DB; untuk development lokal tanpa
+// Redis, jalankan server RESP palsu dengan `go run ./cmd/fakeredis`.
+func newCache() (services.Cache, func()) {
+	switch backend := getEnv("CACHE_BACKEND", "memory"); backend {
+	case "memory":
+		lru := services.NewLRUCache(cacheConfig())
+		return lru, lru.Close
+	case "redis":
+		addr := getEnv("REDIS_ADDR", "localhost:6379")
+		db, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+		client := redis.NewClient(redis.Config{
+			Addr:     addr,
+			Password: os.Getenv("REDIS_PASSWORD"),
+			DB:       db,
+		})
+		if err := client.Ping(context.Background()); err != nil {
+			log.Printf("redis at %s is not reachable yet: %v", addr, err)
+		}
+		return services.NewRemoteCache(client), func() { client.Close() }
+	default:
+		log.Fatalf("unknown CACHE_BACKEND %q", backend)
+		return nil, nil
+	}
+}
+
+// newPrewarmer membaca konfigurasi pre-warm dari env. PREWARM_ROUTES berisi daftar rute
+// dipisah koma, mis. "CGK-DPS,CGK-SUB:business" (kelas kabin default economy). Tanpa
+// PREWARM_ROUTES pre-warm tidak dijalankan.
+func newPrewarmer(aggregator *services.Aggregator) *services.Prewarmer {
+	routesEnv := os.Getenv("PREWARM_ROUTES")
+	if routesEnv == "" {
+		return nil
+	}
+
+	cfg := services.DefaultPrewarmConfig()
+	for _, item := range strings.Split(routesEnv, ",") {
+		route, cabin, _ := strings.Cut(strings.TrimSpace(item), ":")
+		origin, destination, ok := strings.Cut(route, "-")
+		if !ok || origin == "" || destination == "" {
+			log.Printf("invalid PREWARM_ROUTES entry %q", item)
+			continue
+		}
+		if cabin == "" {
+			cabin = "economy"
+		}
+		cfg.Routes = append(cfg.Routes, services.PrewarmRoute{
+			Origin:      strings.ToUpper(origin),
+			Destination: strings.ToUpper(destination),
+			CabinClass:  cabin,
+		})
+	}
+
+	if v := os.Getenv("PREWARM_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Days = n
+		} else {
+			log.Printf("invalid PREWARM_DAYS %q", v)
+		}
+	}
+	cfg.StartDate = os.Getenv("PREWARM_START_DATE")
+	cfg.Interval = envDuration("PREWARM_INTERVAL", cfg.Interval)
+	if v := os.Getenv("PREWARM_RATE"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil && r > 0 {
+			cfg.DefaultRate = r
+		} else {
+			log.Printf("invalid PREWARM_RATE %q", v)
+		}
+	}
+	for i, prefix := range []string{"GARUDA", "LION", "BATIK", "AIRASIA"} {
+		if v := os.Getenv(prefix + "_PREWARM_RATE"); v != "" {
+			if r, err := strconv.ParseFloat(v, 64); err == nil && r > 0 {
+				cfg.RateLimits[aggregator.Providers[i].Name()] = r
+			} else {
+				log.Printf("invalid %s_PREWARM_RATE %q", prefix, v)
+			}
+		}
+	}
+
+	return services.NewPrewarmer(aggregator, cfg)
+}
+
+// cacheConfig membaca batas cache dari env CACHE_MAX_ENTRIES dan CACHE_MAX_BYTES.
+func cacheConfig() services.LRUConfig {
+	cfg := services.DefaultLRUConfig()
+	if v := os.Getenv("CACHE_MAX_ENTRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxEntries = n
+		} else {
+			log.Printf("invalid CACHE_MAX_ENTRIES %q: %v", v, err)
+		}
+	}
+	if v := os.Getenv("CACHE_MAX_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.MaxBytes = n
+		} else {
+			log.Printf("invalid CACHE_MAX_BYTES %q: %v", v, err)
+		}
+	}
+	return cfg
+}
+
+// startEmbeddedStub menjalankan stub airline server di dalam proses yang sama untuk development lokal.
+func startEmbeddedStub() string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatalf("Could not start embedded stub server: %v", err)
+	}
+
+	go func() {
+		if err := http.Serve(ln, stubserver.NewHandler(stubserver.DefaultConfig())); err != nil {
+			log.Printf("embedded stub server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("Embedded stub airline server running on %s", ln.Addr())
+	return "http://" + ln.Addr().String()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bookcabin-test/internal/platform/redis/redistest"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:6379", "listen address")
+	flag.Parse()
+
+	srv, err := redistest.StartServer(*addr)
+	if err != nil {
+		log.Fatalf("Could not listen on %s: %v\n", *addr, err)
+	}
+	fmt.Printf("Fake redis server running on %s\n", srv.Addr())
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	srv.Close()
+}
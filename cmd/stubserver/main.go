@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bookcabin-test/internal/platform/stubserver"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	latencyFactor := flag.Float64("latency-factor", 1, "multiplier applied to every route's simulated latency")
+	errorRate := flag.Float64("error-rate", -1, "override error rate (0..1) for every route; negative keeps the per-airline defaults")
+	authToken := flag.String("auth-token", "", "require this token in each airline's auth header")
+	flag.Parse()
+
+	cfg := stubserver.DefaultConfig()
+	for i := range cfg.Routes {
+		route := &cfg.Routes[i]
+		route.MinLatency = time.Duration(float64(route.MinLatency) * *latencyFactor)
+		route.MaxLatency = time.Duration(float64(route.MaxLatency) * *latencyFactor)
+		if *errorRate >= 0 {
+			route.ErrorRate = *errorRate
+		}
+		route.AuthToken = *authToken
+	}
+
+	fmt.Printf("Stub airline server running on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, stubserver.NewHandler(cfg)); err != nil {
+		log.Fatalf("Could not listen on %s: %v\n", *addr, err)
+	}
+}
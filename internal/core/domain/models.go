@@ -3,46 +3,144 @@ package domain
 import "time"
 
 type SearchCriteria struct {
-	Origin        string        `json:"origin"`
-	Destination   string        `json:"destination"`
-	DepartureDate string        `json:"departureDate"`
-	ReturnDate    *string       `json:"returnDate"`
-	Passengers    int           `json:"passengers"`
-	CabinClass    string        `json:"cabinClass"`
-	Filters       FilterOptions `json:"filters"`
-	SortBy        string        `json:"sortBy"`
+	Origin                 string          `json:"origin"`
+	Destination            string          `json:"destination"`
+	DepartureDate          string          `json:"departureDate"`
+	ReturnDate             *string         `json:"returnDate"`
+	Passengers             int             `json:"passengers"`
+	CabinClass             string          `json:"cabinClass"`
+	IncludeNearbyAirports  bool            `json:"includeNearbyAirports"`
+	NearbyRadiusKm         *float64        `json:"nearbyRadiusKm"`
+	IncludeSelfTransfer    bool            `json:"includeSelfTransfer"`
+	MinSelfTransferMinutes *int            `json:"minSelfTransferMinutes"`
+	DedupMode              string          `json:"dedupMode"`
+	Filters                FilterOptions   `json:"filters"`
+	ReturnFilters          *TimeWindow     `json:"returnFilters"`
+	SortBy                 string          `json:"sortBy"`
+	Sort                   []SortKey       `json:"sort"`
+	ScoringProfile         string          `json:"scoringProfile"`
+	ScoringWeights         *ScoringWeights `json:"scoringWeights"`
+	IncludeFilteredFacets  bool            `json:"includeFilteredFacets"`
+	Limit                  int             `json:"limit,omitempty"`
+	Cursor                 string          `json:"cursor,omitempty"`
 }
 
 type FilterOptions struct {
-	MaxPrice    *float64 `json:"maxPrice"`
-	MinPrice    *float64 `json:"minPrice"`
-	MaxStops    *int     `json:"maxStops"`
-	Airlines    []string `json:"airlines"`
-	MinDuration *int     `json:"minDurationMinutes"`
-	MaxDuration *int     `json:"maxDurationMinutes"`
-	MinDepTime  *string  `json:"minDepTime"`
-	MaxDepTime  *string  `json:"maxDepTime"`
-	MinArrTime  *string  `json:"minArrTime"`
-	MaxArrTime  *string  `json:"maxArrTime"`
+	MaxPrice                *float64 `json:"maxPrice"`
+	MinPrice                *float64 `json:"minPrice"`
+	MaxStops                *int     `json:"maxStops"`
+	Airlines                []string `json:"airlines"`
+	MinDuration             *int     `json:"minDurationMinutes"`
+	MaxDuration             *int     `json:"maxDurationMinutes"`
+	MinDepTime              *string  `json:"minDepTime"`
+	MaxDepTime              *string  `json:"maxDepTime"`
+	MinArrTime              *string  `json:"minArrTime"`
+	MaxArrTime              *string  `json:"maxArrTime"`
+	MaxLayoverMinutes       *int     `json:"maxLayoverMinutes"`
+	MinConnectionMinutes    *int     `json:"minConnectionMinutes"`
+	ExcludedLayoverAirports []string `json:"excludedLayoverAirports"`
+	Expression              string   `json:"expression"`
+	// Compiled adalah Expression yang sudah di-compile, diisi sekali per request agar tidak
+	// di-compile ulang di setiap leg atau hari pencarian.
+	Compiled FlightMatcher `json:"-"`
+}
+
+// FlightMatcher adalah ekspresi filter yang sudah di-compile, lihat filterexpr.Program.
+type FlightMatcher interface {
+	Match(f *UnifiedFlight) bool
+}
+
+// SortKey adalah satu kunci pengurutan; kunci berikutnya hanya dipakai jika kunci sebelumnya seri.
+type SortKey struct {
+	Key       string `json:"key"`
+	Direction string `json:"direction"`
+}
+
+// ScoringWeights menimpa bobot profil scoring untuk satu request; field nil memakai nilai profil.
+type ScoringWeights struct {
+	Price            *float64 `json:"price"`
+	Duration         *float64 `json:"duration"`
+	Stops            *float64 `json:"stops"`
+	DepartureTime    *float64 `json:"departureTime"`
+	Baggage          *float64 `json:"baggage"`
+	Amenities        *float64 `json:"amenities"`
+	AirlineRating    *float64 `json:"airlineRating"`
+	PreferredDepFrom *string  `json:"preferredDepFrom"`
+	PreferredDepTo   *string  `json:"preferredDepTo"`
+}
+
+type TimeWindow struct {
+	MinDepTime *string `json:"minDepTime"`
+	MaxDepTime *string `json:"maxDepTime"`
+	MinArrTime *string `json:"minArrTime"`
+	MaxArrTime *string `json:"maxArrTime"`
 }
 
 type UnifiedFlight struct {
-	ID             string       `json:"id"`
-	Provider       string       `json:"provider"`
-	Airline        AirlineInfo  `json:"airline"`
-	FlightNumber   string       `json:"flight_number"`
-	Departure      FlightPoint  `json:"departure"`
-	Arrival        FlightPoint  `json:"arrival"`
-	Duration       DurationInfo `json:"duration"`
-	Stops          int          `json:"stops"`
-	Price          PriceInfo    `json:"price"`
-	AvailableSeats int          `json:"available_seats"`
-	CabinClass     string       `json:"cabin_class"`
-	Aircraft       string       `json:"aircraft,omitempty"`
-	Amenities      []string     `json:"amenities,omitempty"`
-	Baggage        BaggageInfo  `json:"baggage,omitempty"`
-	Score          float64      `json:"best_value_score,omitempty"`
-	IsValid        bool         `json:"-"`
+	ID               string          `json:"id"`
+	Provider         string          `json:"provider"`
+	Airline          AirlineInfo     `json:"airline"`
+	FlightNumber     string          `json:"flight_number"`
+	Departure        FlightPoint     `json:"departure"`
+	Arrival          FlightPoint     `json:"arrival"`
+	Duration         DurationInfo    `json:"duration"`
+	Stops            int             `json:"stops"`
+	Segments         []Segment       `json:"segments,omitempty"`
+	Layovers         []Layover       `json:"layovers,omitempty"`
+	Price            PriceInfo       `json:"price"`
+	AvailableSeats   int             `json:"available_seats"`
+	CabinClass       string          `json:"cabin_class"`
+	Aircraft         string          `json:"aircraft,omitempty"`
+	Amenities        []string        `json:"amenities,omitempty"`
+	Baggage          BaggageInfo     `json:"baggage,omitempty"`
+	Score            float64         `json:"best_value_score,omitempty"`
+	ScoreBreakdown   *ScoreBreakdown `json:"score_breakdown,omitempty"`
+	MatchedAirports  *AirportMatch   `json:"matched_airports,omitempty"`
+	SelfTransfer     bool            `json:"self_transfer,omitempty"`
+	SelfTransferRisk string          `json:"self_transfer_risk,omitempty"`
+	Offers           []Offer         `json:"offers,omitempty"`
+	IsValid          bool            `json:"-"`
+}
+
+// ScoreBreakdown menjelaskan kontribusi setiap komponen terhadap best_value_score (lebih kecil lebih baik).
+type ScoreBreakdown struct {
+	Profile       string  `json:"profile"`
+	Price         float64 `json:"price"`
+	Duration      float64 `json:"duration"`
+	Stops         float64 `json:"stops"`
+	DepartureTime float64 `json:"departure_time"`
+	Baggage       float64 `json:"baggage"`
+	Amenities     float64 `json:"amenities"`
+	AirlineRating float64 `json:"airline_rating"`
+	Total         float64 `json:"total"`
+}
+
+type Offer struct {
+	Provider       string      `json:"provider"`
+	FlightID       string      `json:"flight_id"`
+	Price          PriceInfo   `json:"price"`
+	AvailableSeats int         `json:"available_seats"`
+	Baggage        BaggageInfo `json:"baggage"`
+}
+
+type Segment struct {
+	FlightNumber     string       `json:"flight_number"`
+	OperatingCarrier string       `json:"operating_carrier"`
+	Departure        FlightPoint  `json:"departure"`
+	Arrival          FlightPoint  `json:"arrival"`
+	Duration         DurationInfo `json:"duration"`
+	Aircraft         string       `json:"aircraft,omitempty"`
+}
+
+type Layover struct {
+	Airport         string `json:"airport"`
+	City            string `json:"city,omitempty"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+type AirportMatch struct {
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
 }
 
 type AirlineInfo struct {
@@ -75,16 +173,162 @@ type PriceInfo struct {
 }
 
 type SearchResponse struct {
-	SearchCriteria SearchCriteria   `json:"search_criteria"`
-	Metadata       ResponseMetadata `json:"metadata"`
-	Flights        []UnifiedFlight  `json:"flights"`
+	SearchCriteria SearchCriteria       `json:"search_criteria"`
+	Metadata       ResponseMetadata     `json:"metadata"`
+	ReturnMetadata *ResponseMetadata    `json:"return_metadata,omitempty"`
+	Facets         *Facets              `json:"facets,omitempty"`
+	FilteredFacets *Facets              `json:"filtered_facets,omitempty"`
+	ReturnFacets   *Facets              `json:"return_facets,omitempty"`
+	Flights        []UnifiedFlight      `json:"flights"`
+	ReturnFlights  []UnifiedFlight      `json:"return_flights,omitempty"`
+	RoundTrips     []RoundTripItinerary `json:"round_trips,omitempty"`
+	NextCursor     string               `json:"next_cursor,omitempty"`
+}
+
+// Facets merangkum hasil pencarian untuk sidebar filter di front end.
+type Facets struct {
+	Total          int               `json:"total"`
+	Airlines       []AirlineFacet    `json:"airlines"`
+	Stops          []StopsFacet      `json:"stops"`
+	PriceRange     *PriceRangeFacet  `json:"price_range,omitempty"`
+	DepartureTimes []TimeBucketFacet `json:"departure_times"`
+	DurationRange  *DurationFacet    `json:"duration_range,omitempty"`
+	Amenities      []AmenityFacet    `json:"amenities"`
+}
+
+type AirlineFacet struct {
+	Name     string  `json:"name"`
+	Code     string  `json:"code"`
+	Count    int     `json:"count"`
+	MinPrice float64 `json:"min_price"`
+}
+
+type StopsFacet struct {
+	Stops    int     `json:"stops"`
+	Count    int     `json:"count"`
+	MinPrice float64 `json:"min_price"`
+}
+
+type PriceRangeFacet struct {
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Currency string  `json:"currency"`
+}
+
+type TimeBucketFacet struct {
+	Bucket   string   `json:"bucket"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Count    int      `json:"count"`
+	MinPrice *float64 `json:"min_price,omitempty"`
+}
+
+type DurationFacet struct {
+	MinMinutes int `json:"min_minutes"`
+	MaxMinutes int `json:"max_minutes"`
+}
+
+type AmenityFacet struct {
+	Amenity string `json:"amenity"`
+	Count   int    `json:"count"`
+}
+
+type FlexibleSearchCriteria struct {
+	SearchCriteria
+	WindowDays int `json:"windowDays"`
+}
+
+type PriceCalendarResponse struct {
+	SearchCriteria FlexibleSearchCriteria `json:"search_criteria"`
+	Days           []DaySummary           `json:"days"`
+	CheapestDate   string                 `json:"cheapest_date,omitempty"`
+	SearchTimeMs   int64                  `json:"search_time_ms"`
+}
+
+type DaySummary struct {
+	Date        string         `json:"date"`
+	OptionCount int            `json:"option_count"`
+	Cheapest    *UnifiedFlight `json:"cheapest,omitempty"`
+	Fastest     *UnifiedFlight `json:"fastest,omitempty"`
+	Providers   []string       `json:"providers"`
+	CacheHit    bool           `json:"cache_hit"`
+}
+
+type MultiCitySearchCriteria struct {
+	Legs                 []LegCriteria   `json:"legs"`
+	Passengers           int             `json:"passengers"`
+	CabinClass           string          `json:"cabinClass"`
+	Filters              FilterOptions   `json:"filters"`
+	SortBy               string          `json:"sortBy"`
+	Sort                 []SortKey       `json:"sort"`
+	ScoringProfile       string          `json:"scoringProfile"`
+	ScoringWeights       *ScoringWeights `json:"scoringWeights"`
+	MinConnectionMinutes *int            `json:"minConnectionMinutes"`
+}
+
+type LegCriteria struct {
+	Origin        string `json:"origin"`
+	Destination   string `json:"destination"`
+	DepartureDate string `json:"departureDate"`
+}
+
+type MultiCitySearchResponse struct {
+	SearchCriteria MultiCitySearchCriteria `json:"search_criteria"`
+	Legs           []SearchResponse        `json:"legs"`
+	Itineraries    []MultiCityItinerary    `json:"itineraries"`
+	SearchTimeMs   int64                   `json:"search_time_ms"`
+}
+
+type MultiCityItinerary struct {
+	ID            string          `json:"id"`
+	Flights       []UnifiedFlight `json:"flights"`
+	TotalPrice    PriceInfo       `json:"total_price"`
+	TotalDuration DurationInfo    `json:"total_duration"`
+	Score         float64         `json:"best_value_score"`
+}
+
+type RoundTripItinerary struct {
+	ID            string        `json:"id"`
+	Outbound      UnifiedFlight `json:"outbound"`
+	Inbound       UnifiedFlight `json:"inbound"`
+	TotalPrice    PriceInfo     `json:"total_price"`
+	TotalDuration DurationInfo  `json:"total_duration"`
+	Score         float64       `json:"best_value_score"`
 }
 
 type ResponseMetadata struct {
-	TotalResults       int   `json:"total_results"`
-	ProvidersQueried   int   `json:"providers_queried"`
-	ProvidersSucceeded int   `json:"providers_succeeded"`
-	ProvidersFailed    int   `json:"providers_failed"`
-	SearchTimeMs       int64 `json:"search_time_ms"`
-	CacheHit           bool  `json:"cache_hit"`
+	TotalResults       int               `json:"total_results"`
+	ProvidersQueried   int               `json:"providers_queried"`
+	ProvidersSucceeded int               `json:"providers_succeeded"`
+	ProvidersFailed    int               `json:"providers_failed"`
+	ProvidersTimedOut  int               `json:"providers_timed_out"`
+	ProvidersCached    int               `json:"providers_cached"`
+	DuplicatesMerged   int               `json:"duplicates_merged"`
+	SearchTimeMs       int64             `json:"search_time_ms"`
+	CacheHit           bool              `json:"cache_hit"`
+	Stale              bool              `json:"stale"`
+	DataAgeMs          int64             `json:"data_age_ms"`
+	Coalesced          bool              `json:"coalesced"`
+	Providers          []ProviderOutcome `json:"providers"`
+}
+
+const (
+	ProviderStatusOK          = "ok"
+	ProviderStatusError       = "error"
+	ProviderStatusTimeout     = "timeout"
+	ProviderStatusCircuitOpen = "circuit_open"
+	ProviderStatusCached      = "cached"
+)
+
+type ProviderOutcome struct {
+	Name         string   `json:"name"`
+	Status       string   `json:"status"`
+	LatencyMs    int64    `json:"latency_ms"`
+	Attempts     int      `json:"attempts"`
+	ResultCount  int      `json:"result_count"`
+	InvalidCount int      `json:"invalid_count"`
+	Hedged       bool     `json:"hedged,omitempty"`
+	CacheAgeMs   int64    `json:"cache_age_ms,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
 }
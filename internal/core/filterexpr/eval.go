@@ -0,0 +1,186 @@
+package filterexpr
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"slices"
+)
+
+type valueType int
+
+const (
+	typeInvalid valueType = iota
+	typeNumber
+	typeString
+	typeBool
+	typeStringList
+	typeNumberList
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeNumber:
+		return "number"
+	case typeString:
+		return "string"
+	case typeBool:
+		return "boolean"
+	case typeStringList:
+		return "list of strings"
+	case typeNumberList:
+		return "list of numbers"
+	default:
+		return "invalid"
+	}
+}
+
+func (t valueType) elem() valueType {
+	switch t {
+	case typeStringList:
+		return typeString
+	case typeNumberList:
+		return typeNumber
+	default:
+		return typeInvalid
+	}
+}
+
+type value struct {
+	num  float64
+	str  string
+	b    bool
+	strs []string
+	nums []float64
+}
+
+// node adalah hasil compile satu bagian ekspresi; tipenya sudah diperiksa saat parsing
+// sehingga eval tidak perlu memeriksa tipe lagi.
+type node struct {
+	typ  valueType
+	eval func(f *domain.UnifiedFlight) value
+	// normalize diisi untuk field list yang isinya dinormalkan, lihat normalizers.
+	normalize func(string) string
+}
+
+func constant(v value, typ valueType) node {
+	return node{typ: typ, eval: func(*domain.UnifiedFlight) value { return v }}
+}
+
+func logical(op token, left, right node) (node, error) {
+	if left.typ != typeBool || right.typ != typeBool {
+		return node{}, newSyntaxError(op.pos, op.display(), "%q needs boolean operands, got %s and %s", op.text, left.typ, right.typ)
+	}
+
+	if op.text == "and" || op.text == "&&" {
+		return node{typ: typeBool, eval: func(f *domain.UnifiedFlight) value {
+			return value{b: left.eval(f).b && right.eval(f).b}
+		}}, nil
+	}
+	return node{typ: typeBool, eval: func(f *domain.UnifiedFlight) value {
+		return value{b: left.eval(f).b || right.eval(f).b}
+	}}, nil
+}
+
+func negate(op token, operand node) (node, error) {
+	if operand.typ != typeBool {
+		return node{}, newSyntaxError(op.pos, op.display(), "%q needs a boolean operand, got %s", op.text, operand.typ)
+	}
+	return node{typ: typeBool, eval: func(f *domain.UnifiedFlight) value {
+		return value{b: !operand.eval(f).b}
+	}}, nil
+}
+
+func compare(op token, left, right node) (node, error) {
+	if left.typ != right.typ {
+		return node{}, newSyntaxError(op.pos, op.display(), "cannot compare %s with %s", left.typ, right.typ)
+	}
+
+	var cmp func(a, b value) int
+	switch left.typ {
+	case typeNumber:
+		cmp = func(a, b value) int {
+			switch {
+			case a.num < b.num:
+				return -1
+			case a.num > b.num:
+				return 1
+			}
+			return 0
+		}
+	case typeString:
+		cmp = func(a, b value) int {
+			switch {
+			case a.str < b.str:
+				return -1
+			case a.str > b.str:
+				return 1
+			}
+			return 0
+		}
+	case typeBool:
+		if op.text != "==" && op.text != "!=" {
+			return node{}, newSyntaxError(op.pos, op.display(), "booleans can only be compared with == or !=")
+		}
+		cmp = func(a, b value) int {
+			if a.b == b.b {
+				return 0
+			}
+			return 1
+		}
+	default:
+		return node{}, newSyntaxError(op.pos, op.display(), "cannot compare lists with %q, use \"in\" instead", op.text)
+	}
+
+	var test func(c int) bool
+	switch op.text {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	default:
+		return node{}, newSyntaxError(op.pos, op.display(), "unknown operator")
+	}
+
+	return node{typ: typeBool, eval: func(f *domain.UnifiedFlight) value {
+		return value{b: test(cmp(left.eval(f), right.eval(f)))}
+	}}, nil
+}
+
+func membership(op token, left, right node, negated bool) (node, error) {
+	elem := right.typ.elem()
+	if elem == typeInvalid {
+		return node{}, newSyntaxError(op.pos, op.display(), "right side of \"in\" must be a list, got %s", right.typ)
+	}
+	// List kosong bertipe list of strings; izinkan juga untuk angka.
+	if left.typ != elem && !(left.typ == typeNumber && right.typ == typeStringList && isEmptyConstant(right)) {
+		return node{}, newSyntaxError(op.pos, op.display(), "cannot look for a %s in a %s", left.typ, right.typ)
+	}
+
+	normalize := right.normalize
+	if normalize == nil {
+		normalize = func(s string) string { return s }
+	}
+
+	return node{typ: typeBool, eval: func(f *domain.UnifiedFlight) value {
+		l, r := left.eval(f), right.eval(f)
+		var found bool
+		if left.typ == typeNumber {
+			found = slices.Contains(r.nums, l.num)
+		} else {
+			found = slices.Contains(r.strs, normalize(l.str))
+		}
+		return value{b: found != negated}
+	}}, nil
+}
+
+func isEmptyConstant(n node) bool {
+	v := n.eval(nil)
+	return len(v.strs) == 0 && len(v.nums) == 0
+}
@@ -0,0 +1,80 @@
+package filterexpr
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"sort"
+	"strings"
+)
+
+type field struct {
+	typ valueType
+	get func(f *domain.UnifiedFlight) value
+}
+
+// fields adalah nama field yang bisa dipakai di ekspresi. Waktu keberangkatan dan kedatangan
+// berupa string "HH:MM" waktu lokal sehingga bisa dibandingkan langsung, mis. departure_time >= "06:00".
+var fields = map[string]field{
+	"price":          {typeNumber, func(f *domain.UnifiedFlight) value { return value{num: f.Price.Amount} }},
+	"duration":       {typeNumber, func(f *domain.UnifiedFlight) value { return value{num: float64(f.Duration.TotalMinutes)} }},
+	"stops":          {typeNumber, func(f *domain.UnifiedFlight) value { return value{num: float64(f.Stops)} }},
+	"seats":          {typeNumber, func(f *domain.UnifiedFlight) value { return value{num: float64(f.AvailableSeats)} }},
+	"max_layover":    {typeNumber, maxLayover},
+	"airline":        {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Airline.Code} }},
+	"airline_name":   {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Airline.Name} }},
+	"provider":       {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Provider} }},
+	"flight_number":  {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.FlightNumber} }},
+	"origin":         {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Departure.Airport} }},
+	"destination":    {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Arrival.Airport} }},
+	"departure_time": {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Departure.TimeOfDay.Format("15:04")} }},
+	"arrival_time":   {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Arrival.TimeOfDay.Format("15:04")} }},
+	"cabin":          {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.CabinClass} }},
+	"aircraft":       {typeString, func(f *domain.UnifiedFlight) value { return value{str: f.Aircraft} }},
+	"self_transfer":  {typeBool, func(f *domain.UnifiedFlight) value { return value{b: f.SelfTransfer} }},
+	"amenities":      {typeStringList, amenities},
+	"layovers":       {typeStringList, layoverAirports},
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizers menormalkan string yang dicari dengan "in" pada field list, sehingga
+// "WiFi" in amenities sama dengan "wifi" in amenities.
+var normalizers = map[string]func(string) string{
+	"amenities": NormalizeAmenity,
+}
+
+// NormalizeAmenity menyamakan penulisan amenity antar provider, mis. "Meal" dan "meal",
+// atau "Power Outlet" dan "power_outlet".
+func NormalizeAmenity(a string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(a)), " ", "_")
+}
+
+func amenities(f *domain.UnifiedFlight) value {
+	res := make([]string, len(f.Amenities))
+	for i, a := range f.Amenities {
+		res[i] = NormalizeAmenity(a)
+	}
+	return value{strs: res}
+}
+
+func layoverAirports(f *domain.UnifiedFlight) value {
+	res := make([]string, len(f.Layovers))
+	for i, l := range f.Layovers {
+		res[i] = l.Airport
+	}
+	return value{strs: res}
+}
+
+func maxLayover(f *domain.UnifiedFlight) value {
+	var longest int
+	for _, l := range f.Layovers {
+		longest = max(longest, l.DurationMinutes)
+	}
+	return value{num: float64(longest)}
+}
@@ -0,0 +1,78 @@
+// Package filterexpr adalah bahasa ekspresi filter kecil untuk UnifiedFlight, mis.
+//
+//	price < 1500000 and (airline in ["GA", "ID"] or stops == 0) and "wifi" in amenities
+//
+// Ekspresi di-compile sekali (lexer, parser dan pemeriksaan tipe) menjadi Program yang bisa
+// dievaluasi berulang kali terhadap setiap penerbangan. Bahasa ini tidak punya pemanggilan
+// fungsi, variabel atau loop sehingga aman dijalankan dari input pengguna.
+package filterexpr
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"fmt"
+	"strings"
+)
+
+const (
+	MaxExpressionLength = 2000
+	maxDepth            = 32
+)
+
+// SyntaxError menunjuk token yang menyebabkan ekspresi ditolak. Position adalah posisi
+// karakter (dimulai dari 1) di dalam ekspresi.
+type SyntaxError struct {
+	Position int    `json:"position"`
+	Token    string `json:"token"`
+	Message  string `json:"message"`
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d near %q", e.Message, e.Position, e.Token)
+}
+
+func newSyntaxError(offset int, tok, format string, args ...any) *SyntaxError {
+	return &SyntaxError{Position: offset + 1, Token: tok, Message: fmt.Sprintf(format, args...)}
+}
+
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile mem-parse dan memeriksa tipe ekspresi. Error yang dikembalikan selalu *SyntaxError.
+func Compile(src string) (*Program, error) {
+	if len([]rune(src)) > MaxExpressionLength {
+		return nil, newSyntaxError(MaxExpressionLength, "", "expression is longer than %d characters", MaxExpressionLength)
+	}
+	if strings.TrimSpace(src) == "" {
+		return nil, newSyntaxError(0, "", "expression is empty")
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, newSyntaxError(tok.pos, tok.display(), "unexpected token")
+	}
+	if root.typ != typeBool {
+		return nil, newSyntaxError(0, src, "expression must evaluate to a boolean, got %s", root.typ)
+	}
+
+	return &Program{source: src, root: root}, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// Match mengevaluasi program terhadap satu penerbangan.
+func (p *Program) Match(f *domain.UnifiedFlight) bool {
+	return p.root.eval(f).b
+}
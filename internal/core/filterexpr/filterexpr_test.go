@@ -0,0 +1,170 @@
+package filterexpr
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFlight() domain.UnifiedFlight {
+	return domain.UnifiedFlight{
+		Provider:     "Garuda Indonesia",
+		Airline:      domain.AirlineInfo{Name: "Garuda Indonesia", Code: "GA"},
+		FlightNumber: "GA400",
+		Departure:    domain.FlightPoint{Airport: "CGK", TimeOfDay: time.Date(2025, 12, 15, 6, 30, 0, 0, time.UTC)},
+		Arrival:      domain.FlightPoint{Airport: "DPS", TimeOfDay: time.Date(2025, 12, 15, 10, 15, 0, 0, time.UTC)},
+		Duration:     domain.DurationInfo{TotalMinutes: 165},
+		Stops:        1,
+		Layovers:     []domain.Layover{{Airport: "SUB", DurationMinutes: 45}},
+		Price:        domain.PriceInfo{Amount: 1250000},
+		CabinClass:   "economy",
+		Amenities:    []string{"Wifi", "Power Outlet"},
+	}
+}
+
+func TestCompileAndMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`price < 1500000`, true},
+		{`price >= 1_250_000 and price <= 1250000.0`, true},
+		{`price > 1500000`, false},
+		{`airline == "GA" && stops == 1`, true},
+		{`airline in ["GA", "ID"] or stops == 0`, true},
+		{`airline not in ['GA']`, false},
+		{`not (airline == "ID") and !self_transfer`, true},
+		{`"wifi" in amenities and "power_outlet" in amenities`, true},
+		{`"meal" in amenities`, false},
+		{`"WiFi" in amenities and " Power Outlet " in amenities`, true},
+		{`"WIFI" not in amenities`, false},
+		{`"sub" in layovers`, false},
+		{`"SUB" in layovers and max_layover <= 60`, true},
+		{`stops in [0, 1]`, true},
+		{`stops in []`, false},
+		{`departure_time >= "06:00" and arrival_time < "10:30"`, true},
+		{`origin == "CGK" and destination != "CGK" and cabin == "economy"`, true},
+		{`self_transfer == false || duration > 600`, true},
+		{`flight_number == "GA4"`, false},
+		{`provider == "Garuda Indonesia"`, true},
+		{`true`, true},
+		{`(((price < 2000000)))`, true},
+	}
+
+	f := testFlight()
+	for _, tt := range tests {
+		p, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := p.Match(&f); got != tt.want {
+			t.Errorf("Compile(%q).Match = %v, want %v", tt.expr, got, tt.want)
+		}
+		if p.String() != tt.expr {
+			t.Errorf("String() = %q, want %q", p.String(), tt.expr)
+		}
+	}
+}
+
+func TestCompileSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		expr     string
+		message  string
+		position int
+		token    string
+	}{
+		// lexer
+		{`price < 12abc`, "invalid number", 9, "12a"},
+		{`price < 1.2.3`, "invalid number", 9, "1.2.3"},
+		{`price # 1`, "unexpected character", 7, "#"},
+		{`price = 1`, `unexpected character, did you mean "=="?`, 7, "="},
+		{`true & true`, `unexpected character, did you mean "&&"?`, 6, "&"},
+		{`true | true`, `unexpected character, did you mean "||"?`, 6, "|"},
+		{`airline == "G\xA"`, "unknown escape sequence", 14, `\x`},
+		{`airline == "GA`, "unterminated string", 12, `"GA`},
+
+		// Compile
+		{`   `, "expression is empty", 1, ""},
+		{`price > 1 2`, "unexpected token", 11, "2"},
+		{`price`, "expression must evaluate to a boolean, got number", 1, "price"},
+		{`amenities`, "expression must evaluate to a boolean, got list of strings", 1, "amenities"},
+
+		// parser
+		{`(price > 1`, `expected ")" to close "(" at position 1`, 11, "end of expression"},
+		{`price > and`, "expected a value", 9, "and"},
+		{`price > )`, "expected a value", 9, ")"},
+		{`price >`, "unexpected end of expression, expected a value", 8, "end of expression"},
+		{`speed > 1`, "unknown field, expected one of aircraft, airline, airline_name", 1, "speed"},
+		{`airline in [price]`, "list elements must be string or number literals", 13, "price"},
+		{`airline in ["GA", 1]`, "list mixes string and number elements", 19, "1"},
+		{`airline in ["GA" "ID"]`, `expected "," or "]" to close "[" at position 12`, 18, `"ID"`},
+
+		// pemeriksaan tipe
+		{`price and true`, `"and" needs boolean operands, got number and boolean`, 7, "and"},
+		{`true || stops`, `"||" needs boolean operands, got boolean and number`, 6, "||"},
+		{`not price`, `"not" needs a boolean operand, got number`, 1, "not"},
+		{`price == "1"`, "cannot compare number with string", 7, "=="},
+		{`self_transfer < true`, "booleans can only be compared with == or !=", 15, "<"},
+		{`amenities == layovers`, `cannot compare lists with "==", use "in" instead`, 11, "=="},
+		{`"GA" in airline`, `right side of "in" must be a list, got string`, 6, "in"},
+		{`price in ["GA"]`, "cannot look for a number in a list of strings", 7, "in"},
+		{`"GA" not in [1]`, "cannot look for a string in a list of numbers", 6, "not"},
+	}
+
+	for _, tt := range tests {
+		_, err := Compile(tt.expr)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Compile(%q) error = %v, want *SyntaxError", tt.expr, err)
+			continue
+		}
+		if !strings.HasPrefix(syntaxErr.Message, tt.message) || syntaxErr.Position != tt.position || syntaxErr.Token != tt.token {
+			t.Errorf("Compile(%q) = {%q, %d, %q}, want {%q, %d, %q}", tt.expr,
+				syntaxErr.Message, syntaxErr.Position, syntaxErr.Token, tt.message, tt.position, tt.token)
+		}
+	}
+}
+
+func TestCompileDepthLimit(t *testing.T) {
+	nested := func(n int) string {
+		return strings.Repeat("(", n) + "price > 1" + strings.Repeat(")", n)
+	}
+
+	if _, err := Compile(nested(maxDepth - 1)); err != nil {
+		t.Fatalf("nesting %d: %v", maxDepth-1, err)
+	}
+
+	var syntaxErr *SyntaxError
+	if _, err := Compile(nested(maxDepth)); !errors.As(err, &syntaxErr) {
+		t.Fatalf("nesting %d: got %v, want *SyntaxError", maxDepth, err)
+	}
+	if syntaxErr.Message != "expression is nested too deeply" || syntaxErr.Position != maxDepth+1 || syntaxErr.Token != "price" {
+		t.Errorf("got %+v", syntaxErr)
+	}
+
+	if _, err := Compile(strings.Repeat("not ", maxDepth-1) + "true"); err != nil {
+		t.Fatalf("%d negations: %v", maxDepth-1, err)
+	}
+	_, err := Compile(strings.Repeat("not ", maxDepth) + "true")
+	if !errors.As(err, &syntaxErr) || syntaxErr.Message != "expression is nested too deeply" || syntaxErr.Position != 4*(maxDepth-1)+1 {
+		t.Errorf("%d negations: got %v", maxDepth, err)
+	}
+}
+
+func TestCompileMaxLength(t *testing.T) {
+	expr := "price > 1" + strings.Repeat(" ", MaxExpressionLength-len("price > 1"))
+	if _, err := Compile(expr); err != nil {
+		t.Fatalf("expression of %d characters: %v", MaxExpressionLength, err)
+	}
+
+	var syntaxErr *SyntaxError
+	if _, err := Compile(expr + " "); !errors.As(err, &syntaxErr) {
+		t.Fatalf("got %v, want *SyntaxError", err)
+	}
+	if syntaxErr.Message != "expression is longer than 2000 characters" || syntaxErr.Position != MaxExpressionLength+1 {
+		t.Errorf("got %+v", syntaxErr)
+	}
+}
@@ -0,0 +1,144 @@
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	// text adalah potongan asli dari ekspresi; untuk string berisi nilai yang sudah di-unescape.
+	text string
+	pos  int
+}
+
+// display mengembalikan token seperti yang ditulis pengguna, untuk pesan error.
+func (t token) display() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return t.text
+	}
+}
+
+var twoCharOps = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')' || r == '[' || r == ']' || r == ',':
+			kinds := map[rune]tokenKind{'(': tokenLParen, ')': tokenRParen, '[': tokenLBracket, ']': tokenRBracket, ',': tokenComma}
+			tokens = append(tokens, token{kind: kinds[r], text: string(r), pos: i})
+			i++
+
+		case r == '"' || r == '\'':
+			text, next, err := lexString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = next
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			if i < len(runes) && (unicode.IsLetter(runes[i])) {
+				return nil, newSyntaxError(start, string(runes[start:i+1]), "invalid number")
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		default:
+			op := ""
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				for _, candidate := range twoCharOps {
+					if pair == candidate {
+						op = pair
+						break
+					}
+				}
+			}
+			if op == "" && strings.ContainsRune("<>!", r) {
+				op = string(r)
+			}
+			if op == "" {
+				msg := "unexpected character"
+				if r == '=' || r == '&' || r == '|' {
+					msg = fmt.Sprintf("unexpected character, did you mean %q?", string(r)+string(r))
+				}
+				return nil, newSyntaxError(i, string(r), "%s", msg)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len([]rune(op))
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// lexString membaca literal string yang diawali tanda kutip di runes[start], mendukung escape \" \' \\ \n \t.
+func lexString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var sb strings.Builder
+
+	for i := start + 1; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case quote:
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(runes) {
+				break
+			}
+			i++
+			switch esc := runes[i]; esc {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case '\\', '"', '\'':
+				sb.WriteRune(esc)
+			default:
+				return "", 0, newSyntaxError(i-1, string(runes[i-1:i+1]), "unknown escape sequence")
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return "", 0, newSyntaxError(start, string(runes[start:]), "unterminated string")
+}
@@ -0,0 +1,241 @@
+package filterexpr
+
+import (
+	"strconv"
+	"strings"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func isKeyword(tok token, words ...string) bool {
+	if tok.kind != tokenIdent && tok.kind != tokenOp {
+		return false
+	}
+	for _, w := range words {
+		if tok.text == w {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExpression: or := and (("or" | "||") and)*
+func (p *parser) parseExpression() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		tok := p.peek()
+		return node{}, newSyntaxError(tok.pos, tok.display(), "expression is nested too deeply")
+	}
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return node{}, err
+	}
+	for isKeyword(p.peek(), "or", "||") {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return node{}, err
+		}
+		if left, err = logical(op, left, right); err != nil {
+			return node{}, err
+		}
+	}
+	return left, nil
+}
+
+// parseAnd: and := unary (("and" | "&&") unary)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return node{}, err
+	}
+	for isKeyword(p.peek(), "and", "&&") {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+		if left, err = logical(op, left, right); err != nil {
+			return node{}, err
+		}
+	}
+	return left, nil
+}
+
+// parseUnary: unary := ("not" | "!") unary | comparison
+func (p *parser) parseUnary() (node, error) {
+	if isKeyword(p.peek(), "not", "!") {
+		op := p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxDepth {
+			return node{}, newSyntaxError(op.pos, op.display(), "expression is nested too deeply")
+		}
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+		return negate(op, operand)
+	}
+	return p.parseComparison()
+}
+
+// parseComparison: comparison := operand [op operand], dengan op salah satu
+// == != < <= > >= in "not in".
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return node{}, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenOp && tok.text != "!" && tok.text != "&&" && tok.text != "||":
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return node{}, err
+		}
+		return compare(tok, left, right)
+
+	case isKeyword(tok, "in"):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return node{}, err
+		}
+		return membership(tok, left, right, false)
+
+	case isKeyword(tok, "not") && isKeyword(p.tokens[p.pos+1], "in"):
+		p.next()
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return node{}, err
+		}
+		return membership(tok, left, right, true)
+	}
+
+	return left, nil
+}
+
+// parseOperand: operand := NUMBER | STRING | true | false | FIELD | list | "(" expression ")"
+func (p *parser) parseOperand() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return numberLiteral(tok)
+
+	case tokenString:
+		return constant(value{str: tok.text}, typeString), nil
+
+	case tokenLBracket:
+		return p.parseList(tok)
+
+	case tokenLParen:
+		inner, err := p.parseExpression()
+		if err != nil {
+			return node{}, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return node{}, newSyntaxError(closing.pos, closing.display(), "expected \")\" to close \"(\" at position %d", tok.pos+1)
+		}
+		return inner, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return constant(value{b: tok.text == "true"}, typeBool), nil
+		case "and", "or", "not", "in":
+			return node{}, newSyntaxError(tok.pos, tok.display(), "expected a value")
+		}
+		f, ok := fields[tok.text]
+		if !ok {
+			return node{}, newSyntaxError(tok.pos, tok.display(), "unknown field, expected one of %s", strings.Join(fieldNames(), ", "))
+		}
+		return node{typ: f.typ, eval: f.get, normalize: normalizers[tok.text]}, nil
+
+	case tokenEOF:
+		return node{}, newSyntaxError(tok.pos, tok.display(), "unexpected end of expression, expected a value")
+
+	default:
+		return node{}, newSyntaxError(tok.pos, tok.display(), "expected a value")
+	}
+}
+
+// parseList membaca literal list berisi string atau angka, mis. ["GA", "ID"] atau [0, 1].
+func (p *parser) parseList(open token) (node, error) {
+	var strs []string
+	var nums []float64
+	elemType := typeInvalid
+
+	if p.peek().kind == tokenRBracket {
+		p.next()
+		return constant(value{}, typeStringList), nil
+	}
+
+	for {
+		tok := p.next()
+		var typ valueType
+		switch tok.kind {
+		case tokenString:
+			typ = typeString
+			strs = append(strs, tok.text)
+		case tokenNumber:
+			n, err := numberLiteral(tok)
+			if err != nil {
+				return node{}, err
+			}
+			typ = typeNumber
+			nums = append(nums, n.eval(nil).num)
+		default:
+			return node{}, newSyntaxError(tok.pos, tok.display(), "list elements must be string or number literals")
+		}
+
+		if elemType != typeInvalid && typ != elemType {
+			return node{}, newSyntaxError(tok.pos, tok.display(), "list mixes %s and %s elements", elemType, typ)
+		}
+		elemType = typ
+
+		sep := p.next()
+		if sep.kind == tokenRBracket {
+			break
+		}
+		if sep.kind != tokenComma {
+			return node{}, newSyntaxError(sep.pos, sep.display(), "expected \",\" or \"]\" to close \"[\" at position %d", open.pos+1)
+		}
+	}
+
+	if elemType == typeNumber {
+		return constant(value{nums: nums}, typeNumberList), nil
+	}
+	return constant(value{strs: strs}, typeStringList), nil
+}
+
+func numberLiteral(tok token) (node, error) {
+	n, err := strconv.ParseFloat(strings.ReplaceAll(tok.text, "_", ""), 64)
+	if err != nil {
+		return node{}, newSyntaxError(tok.pos, tok.display(), "invalid number")
+	}
+	return constant(value{num: n}, typeNumber), nil
+}
@@ -3,82 +3,185 @@ package services
 import (
 	"bookcabin-test/internal/core/domain"
 	"bookcabin-test/internal/platform/providers"
+	"context"
 	"sync"
 	"time"
 )
 
 const CacheExpiration = 60 * time.Second
+const DefaultStaleGrace = 2 * time.Minute
+const FetchTimeout = 500 * time.Millisecond
 const filterTimeLayout = "15:04"
 
+// CachedResponse adalah hasil satu provider untuk satu rute, tanggal dan kelas kabin, atau
+// snapshot hasil pencarian yang sudah diurutkan untuk pagination (Snapshot tidak nil).
 type CachedResponse struct {
+	Provider  string
 	Flights   []domain.UnifiedFlight
+	Outcome   domain.ProviderOutcome
+	Snapshot  *domain.SearchResponse
 	Timestamp time.Time
+	TTL       time.Duration
+	// ReuseUntil adalah batas snapshot boleh dipakai ulang untuk halaman pertama, yaitu saat entry
+	// provider tertua yang membentuknya tidak lagi fresh. Cursor tetap memakai snapshot sampai TTL.
+	ReuseUntil time.Time
 }
 
 type Aggregator struct {
-	Providers   []providers.ProviderInterface
-	FlightCache *sync.Map
+	Providers    []providers.ProviderInterface
+	FlightCache  Cache
+	SelfTransfer SelfTransferConfig
+	Hedging      HedgeConfig
+	StaleGrace   time.Duration
+	// CacheTTLs mengatur TTL cache per nama provider; provider yang tidak terdaftar memakai CacheExpiration.
+	CacheTTLs map[string]time.Duration
+
+	latencies sync.Map
+	fetches   fetchGroup
 }
 
 func NewAggregator(providers []providers.ProviderInterface) *Aggregator {
+	return NewAggregatorWithCache(providers, NewLRUCache(DefaultLRUConfig()))
+}
+
+func NewAggregatorWithCache(providers []providers.ProviderInterface, cache Cache) *Aggregator {
 	return &Aggregator{
-		Providers:   providers,
-		FlightCache: &sync.Map{},
+		Providers:    providers,
+		FlightCache:  cache,
+		SelfTransfer: DefaultSelfTransferConfig(),
+		Hedging:      DefaultHedgeConfig(),
+		StaleGrace:   DefaultStaleGrace,
+		CacheTTLs:    map[string]time.Duration{},
 	}
 }
 
-func (a *Aggregator) SearchFlights(criteria domain.SearchCriteria) domain.SearchResponse {
-	start := time.Now()
-	cacheKey := criteriaHash(criteria)
-
-	if cachedVal, ok := a.FlightCache.Load(cacheKey); ok {
-		cached := cachedVal.(CachedResponse)
-		if time.Since(cached.Timestamp) < CacheExpiration {
-			filteredFlights := filterFlights(cached.Flights, criteria)
-			scoredFlights := calculateBestValue(filteredFlights)
-			sortedFlights := sortFlights(scoredFlights, criteria.SortBy)
-
-			return domain.SearchResponse{
-				SearchCriteria: criteria,
-				Flights:        sortedFlights,
-				Metadata: domain.ResponseMetadata{
-					TotalResults:       len(sortedFlights),
-					ProvidersQueried:   len(a.Providers),
-					ProvidersSucceeded: len(a.Providers),
-					ProvidersFailed:    0,
-					SearchTimeMs:       time.Since(start).Milliseconds(),
-					CacheHit:           true,
-				},
-			}
-		} else {
-			a.FlightCache.Delete(cacheKey)
-		}
+func (a *Aggregator) SearchFlights(ctx context.Context, criteria domain.SearchCriteria) domain.SearchResponse {
+	criteria.Filters = compileFilters(criteria.Filters)
+	if criteria.ReturnDate != nil && *criteria.ReturnDate != "" {
+		return a.searchRoundTrip(ctx, criteria)
 	}
+	return a.searchOneWay(ctx, criteria)
+}
 
-	flights, providersSucceeded := a.fetchInParallel(criteria)
+func (a *Aggregator) searchOneWay(ctx context.Context, criteria domain.SearchCriteria) domain.SearchResponse {
+	start := time.Now()
+	flights, metadata := a.loadFlights(ctx, criteria)
+
+	if criteria.IncludeSelfTransfer {
+		flights = append(append([]domain.UnifiedFlight{}, flights...), a.buildSelfTransfers(ctx, criteria)...)
+	}
 
-	a.FlightCache.Store(cacheKey, CachedResponse{
-		Flights:   flights,
-		Timestamp: time.Now(),
-	})
+	if criteria.DedupMode != DedupModeRaw {
+		var merged int
+		flights, merged = dedupFlights(flights, criteria.Passengers)
+		metadata.DuplicatesMerged = merged
+	}
 
+	facets := computeFacets(filterFlights(flights, unfilteredCriteria(criteria)))
 	filteredFlights := filterFlights(flights, criteria)
-	scoredFlights := calculateBestValue(filteredFlights)
-	sortedFlights := sortFlights(scoredFlights, criteria.SortBy)
 
-	providersQueried := len(a.Providers)
-	providersFailed := providersQueried - providersSucceeded
+	var filteredFacets *domain.Facets
+	if criteria.IncludeFilteredFacets {
+		filteredFacets = computeFacets(filteredFlights)
+	}
+
+	scoredFlights := calculateBestValue(filteredFlights, scoringFor(criteria))
+	sortedFlights := sortFlights(scoredFlights, sortSpecFor(criteria.SortBy, criteria.Sort))
+
+	metadata.TotalResults = len(sortedFlights)
+	metadata.SearchTimeMs = time.Since(start).Milliseconds()
 
 	return domain.SearchResponse{
 		SearchCriteria: criteria,
 		Flights:        sortedFlights,
-		Metadata: domain.ResponseMetadata{
-			TotalResults:       len(sortedFlights),
-			ProvidersQueried:   providersQueried,
-			ProvidersSucceeded: providersSucceeded,
-			ProvidersFailed:    providersFailed,
-			SearchTimeMs:       time.Since(start).Milliseconds(),
-			CacheHit:           false,
-		},
+		Metadata:       metadata,
+		Facets:         facets,
+		FilteredFacets: filteredFacets,
+	}
+}
+
+// loadFlights mengambil data penerbangan mentah (sebelum filter). Kode metro dan bandara terdekat
+// diperluas ke rute konkret, dan setiap provider punya entry cache sendiri per rute, sehingga hanya
+// pasangan provider/rute yang tidak ada di cache yang di-fetch ulang.
+func (a *Aggregator) loadFlights(ctx context.Context, criteria domain.SearchCriteria) ([]domain.UnifiedFlight, domain.ResponseMetadata) {
+	now := time.Now()
+	targets := a.fetchTargets(criteria)
+	results := make([]providerResult, len(targets))
+	metadata := domain.ResponseMetadata{ProvidersQueried: len(a.Providers)}
+	var missing []fetchTarget
+	var missingAt []int
+
+	for t, target := range targets {
+		cached, ok := a.FlightCache.Load(ctx, providerCacheKey(a.Providers[target.index].Name(), target.criteria))
+		if !ok || !now.Before(cached.freshUntil().Add(a.StaleGrace)) {
+			missing = append(missing, target)
+			missingAt = append(missingAt, t)
+			continue
+		}
+
+		if !now.Before(cached.freshUntil()) {
+			metadata.Stale = true
+			a.refreshInBackground(target.index, target.criteria)
+		}
+
+		age := now.Sub(cached.Timestamp).Milliseconds()
+		if age > metadata.DataAgeMs {
+			metadata.DataAgeMs = age
+		}
+		results[t] = providerResult{index: target.index, flights: cached.Flights, outcome: cachedOutcome(cached.Outcome, age)}
+	}
+
+	if len(missing) > 0 {
+		fetched, coalesced := a.fetchInParallel(ctx, missing)
+		for j, r := range fetched {
+			results[missingAt[j]] = r
+		}
+		metadata.Coalesced = coalesced
+	}
+	metadata.CacheHit = len(missing) == 0
+
+	var flights []domain.UnifiedFlight
+	metadata.Providers = make([]domain.ProviderOutcome, len(a.Providers))
+	for i := range a.Providers {
+		var parts []providerResult
+		var routes []domain.SearchCriteria
+		for t, target := range targets {
+			if target.index != i {
+				continue
+			}
+			r := results[t]
+			if isSuccess(r.outcome.Status) {
+				r.flights = routeFlights(r.flights, target.criteria)
+				r.outcome.ResultCount = len(r.flights)
+				flights = append(flights, r.flights...)
+			}
+			parts = append(parts, r)
+			routes = append(routes, target.criteria)
+		}
+		outcome := mergeRouteOutcomes(parts, routes)
+		metadata.Providers[i] = outcome
+
+		switch outcome.Status {
+		case domain.ProviderStatusOK, domain.ProviderStatusCached:
+			metadata.ProvidersSucceeded++
+			if outcome.Status == domain.ProviderStatusCached {
+				metadata.ProvidersCached++
+			}
+		case domain.ProviderStatusTimeout:
+			metadata.ProvidersTimedOut++
+		}
+	}
+	metadata.ProvidersFailed = metadata.ProvidersQueried - metadata.ProvidersSucceeded
+
+	return flights, metadata
+}
+
+func (a *Aggregator) BreakerStates() []providers.BreakerSnapshot {
+	res := []providers.BreakerSnapshot{}
+	for _, p := range a.Providers {
+		if reporter, ok := p.(providers.BreakerReporter); ok {
+			res = append(res, reporter.BreakerSnapshot())
+		}
 	}
+	return res
 }
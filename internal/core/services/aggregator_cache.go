@@ -2,14 +2,61 @@ package services
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"time"
 )
 
-func criteriaHash(c domain.SearchCriteria) string {
-	key := fmt.Sprintf("%s_%s_%s_%s", c.Origin, c.Destination, c.DepartureDate, c.CabinClass)
+// providerCacheKey membentuk key cache untuk hasil satu provider pada rute, tanggal dan kelas kabin tertentu.
+func providerCacheKey(provider string, c domain.SearchCriteria) string {
+	key := fmt.Sprintf("%s_%s_%s_%s_%s", provider, c.Origin, c.Destination, c.DepartureDate, c.CabinClass)
 	hasher := sha256.New()
 	hasher.Write([]byte(key))
 	return hex.EncodeToString(hasher.Sum(nil))
 }
+
+func cachedOutcome(o domain.ProviderOutcome, ageMs int64) domain.ProviderOutcome {
+	o.Status = domain.ProviderStatusCached
+	o.LatencyMs = 0
+	o.Attempts = 0
+	o.Hedged = false
+	o.CacheAgeMs = ageMs
+	return o
+}
+
+func (c CachedResponse) freshUntil() time.Time {
+	return c.Timestamp.Add(c.TTL)
+}
+
+func (a *Aggregator) cacheTTL(provider string) time.Duration {
+	if ttl, ok := a.CacheTTLs[provider]; ok && ttl > 0 {
+		return ttl
+	}
+	return CacheExpiration
+}
+
+// storeProviderResult menyimpan hasil provider yang sukses. Hasil yang gagal tidak di-cache
+// agar provider tersebut di-fetch ulang pada request berikutnya.
+func (a *Aggregator) storeProviderResult(ctx context.Context, criteria domain.SearchCriteria, r providerResult) {
+	if r.outcome.Status != domain.ProviderStatusOK {
+		return
+	}
+
+	entry := CachedResponse{
+		Provider:  r.outcome.Name,
+		Flights:   r.flights,
+		Outcome:   r.outcome,
+		Timestamp: time.Now(),
+		TTL:       a.cacheTTL(r.outcome.Name),
+	}
+	a.FlightCache.Store(ctx, providerCacheKey(entry.Provider, criteria), entry, entry.TTL+a.StaleGrace)
+}
+
+// refreshInBackground memperbarui entry cache provider yang stale tanpa menunggu hasilnya.
+// Refresh untuk key yang sama digabung oleh fetchGroup, sehingga hanya satu yang berjalan, dan
+// refresh bersifat detached: tidak ikut dibatalkan ketika client yang memicunya terputus.
+func (a *Aggregator) refreshInBackground(index int, criteria domain.SearchCriteria) {
+	a.startFetch(context.Background(), index, criteria, true)
+}
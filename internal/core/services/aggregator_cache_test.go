@@ -0,0 +1,154 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func cacheTestProvider(name string) *stubProvider {
+	return &stubProvider{name: name, search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		f := stubFlight(name+"1", c.Origin, c.Destination, day.Add(8*time.Hour), 110, 900000)
+		f.Provider = name
+		return []domain.UnifiedFlight{f}, nil
+	}}
+}
+
+func waitForCalls(t *testing.T, p *stubProvider, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for p.calls.Load() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s called %d times, want %d", p.name, p.calls.Load(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	p := cacheTestProvider("Stub")
+	a := newTestAggregator(p)
+	a.CacheTTLs["Stub"] = 100 * time.Millisecond
+	a.StaleGrace = time.Minute
+	criteria := domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15"}
+
+	resp := a.SearchFlights(context.Background(), criteria)
+	if resp.Metadata.Stale || resp.Metadata.CacheHit || resp.Metadata.Providers[0].Status != domain.ProviderStatusOK {
+		t.Fatalf("first search metadata = %+v, want a live fetch", resp.Metadata)
+	}
+
+	// Lewat TTL tapi masih dalam grace: data lama dikembalikan dan satu refresh berjalan di background.
+	time.Sleep(120 * time.Millisecond)
+	resp = a.SearchFlights(context.Background(), criteria)
+	if !resp.Metadata.Stale || !resp.Metadata.CacheHit || len(resp.Flights) != 1 {
+		t.Fatalf("stale search metadata = %+v with %d flights, want stale cache hit", resp.Metadata, len(resp.Flights))
+	}
+	if resp.Metadata.DataAgeMs < 100 {
+		t.Errorf("data age = %dms, want at least the TTL", resp.Metadata.DataAgeMs)
+	}
+	waitForCalls(t, p, 2)
+	deadline := time.Now().Add(time.Second)
+	for {
+		cached, ok := a.FlightCache.Load(context.Background(), providerCacheKey("Stub", criteria))
+		if ok && time.Now().Before(cached.freshUntil()) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not store a fresh entry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	resp = a.SearchFlights(context.Background(), criteria)
+	if resp.Metadata.Stale || !resp.Metadata.CacheHit {
+		t.Errorf("search after refresh metadata = %+v, want fresh cache hit", resp.Metadata)
+	}
+	if got := p.calls.Load(); got != 2 {
+		t.Errorf("provider called %d times, want 2", got)
+	}
+}
+
+func TestStaleEntryBeyondGraceIsFetchedAgain(t *testing.T) {
+	p := cacheTestProvider("Stub")
+	a := newTestAggregator(p)
+	a.CacheTTLs["Stub"] = 10 * time.Millisecond
+	a.StaleGrace = 10 * time.Millisecond
+	criteria := domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15"}
+
+	a.SearchFlights(context.Background(), criteria)
+	time.Sleep(30 * time.Millisecond)
+
+	resp := a.SearchFlights(context.Background(), criteria)
+	if resp.Metadata.Stale || resp.Metadata.CacheHit || resp.Metadata.Providers[0].Status != domain.ProviderStatusOK {
+		t.Errorf("metadata = %+v, want a live fetch", resp.Metadata)
+	}
+	if got := p.calls.Load(); got != 2 {
+		t.Errorf("provider called %d times, want 2", got)
+	}
+}
+
+func TestProviderTTLsExpireIndependently(t *testing.T) {
+	fast, slow := cacheTestProvider("Fast"), cacheTestProvider("Slow")
+	a := newTestAggregator(fast, slow)
+	a.CacheTTLs["Fast"] = 20 * time.Millisecond
+	a.CacheTTLs["Slow"] = time.Hour
+	a.StaleGrace = 0
+	criteria := domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15"}
+
+	a.SearchFlights(context.Background(), criteria)
+	time.Sleep(40 * time.Millisecond)
+
+	resp := a.SearchFlights(context.Background(), criteria)
+	if fast.calls.Load() != 2 || slow.calls.Load() != 1 {
+		t.Fatalf("calls: fast %d, slow %d; want 2 and 1", fast.calls.Load(), slow.calls.Load())
+	}
+	if len(resp.Flights) != 2 {
+		t.Errorf("got %d flights, want 2", len(resp.Flights))
+	}
+
+	m := resp.Metadata
+	if m.CacheHit || m.Stale || m.ProvidersSucceeded != 2 || m.ProvidersCached != 1 || m.ProvidersFailed != 0 {
+		t.Errorf("metadata = %+v, want one live and one cached provider", m)
+	}
+	live, cached := m.Providers[0], m.Providers[1]
+	if live.Name != "Fast" || live.Status != domain.ProviderStatusOK || live.CacheAgeMs != 0 {
+		t.Errorf("fast outcome = %+v, want a live fetch", live)
+	}
+	if cached.Name != "Slow" || cached.Status != domain.ProviderStatusCached || cached.CacheAgeMs < 40 || cached.Attempts != 0 {
+		t.Errorf("slow outcome = %+v, want cached for at least 40ms", cached)
+	}
+	if m.DataAgeMs != cached.CacheAgeMs {
+		t.Errorf("data age = %dms, want the cached provider's age %dms", m.DataAgeMs, cached.CacheAgeMs)
+	}
+}
+
+func TestMergeRouteOutcomes(t *testing.T) {
+	routes := []domain.SearchCriteria{{Origin: "CGK", Destination: "DPS"}, {Origin: "HLP", Destination: "DPS"}}
+	cached := providerResult{outcome: domain.ProviderOutcome{Name: "Stub", Status: domain.ProviderStatusCached, CacheAgeMs: 4000, ResultCount: 2}}
+	cachedOld := providerResult{outcome: domain.ProviderOutcome{Name: "Stub", Status: domain.ProviderStatusCached, CacheAgeMs: 9000, ResultCount: 1}}
+	live := providerResult{outcome: domain.ProviderOutcome{Name: "Stub", Status: domain.ProviderStatusOK, LatencyMs: 120, Attempts: 1, ResultCount: 3}}
+	timedOut := providerResult{outcome: domain.ProviderOutcome{Name: "Stub", Status: domain.ProviderStatusTimeout, Error: "deadline exceeded", Attempts: 2}}
+
+	tests := []struct {
+		name     string
+		parts    []providerResult
+		status   string
+		ageMs    int64
+		results  int
+		warnings int
+	}{
+		{"cached and live", []providerResult{cached, live}, domain.ProviderStatusOK, 4000, 5, 0},
+		{"all cached", []providerResult{cached, cachedOld}, domain.ProviderStatusCached, 9000, 3, 0},
+		{"cached and failed", []providerResult{cachedOld, timedOut}, domain.ProviderStatusOK, 9000, 1, 1},
+		{"all failed", []providerResult{timedOut, timedOut}, domain.ProviderStatusTimeout, 0, 0, 2},
+	}
+
+	for _, tt := range tests {
+		o := mergeRouteOutcomes(tt.parts, routes)
+		if o.Status != tt.status || o.CacheAgeMs != tt.ageMs || o.ResultCount != tt.results || len(o.Warnings) != tt.warnings {
+			t.Errorf("%s: got status %s, age %d, results %d, warnings %v", tt.name, o.Status, o.CacheAgeMs, o.ResultCount, o.Warnings)
+		}
+	}
+}
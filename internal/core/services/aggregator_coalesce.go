@@ -0,0 +1,154 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type CoalescingStats struct {
+	Fetches   int64 `json:"fetches"`
+	Coalesced int64 `json:"coalesced"`
+}
+
+type inflightFetch struct {
+	done   chan struct{}
+	start  time.Time
+	stats  *providers.CallStats
+	result providerResult
+
+	// Dijaga oleh fetchGroup.mu. waiters menghitung request yang masih menunggu; ketika semuanya
+	// pergi, panggilan dibatalkan kecuali ada pemanggil detached (refresh background).
+	key      string
+	waiters  int
+	detached bool
+	cancel   context.CancelFunc
+}
+
+// fetchGroup memastikan hanya ada satu panggilan ke provider per cache key pada satu waktu
+// (mirip singleflight); request lain dengan key yang sama menunggu dan memakai hasil yang sama.
+type fetchGroup struct {
+	mu        sync.Mutex
+	calls     map[string]*inflightFetch
+	fetches   atomic.Int64
+	coalesced atomic.Int64
+}
+
+func (g *fetchGroup) join(key string, stats *providers.CallStats, cancel context.CancelFunc, detached bool) (*inflightFetch, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		// Refresh background bukan request client, jadi tidak dihitung sebagai coalesced.
+		if !detached {
+			g.coalesced.Add(1)
+		}
+		call.addWaiter(detached)
+		return call, false
+	}
+
+	if g.calls == nil {
+		g.calls = map[string]*inflightFetch{}
+	}
+	call := &inflightFetch{done: make(chan struct{}), start: time.Now(), stats: stats, key: key, cancel: cancel}
+	call.addWaiter(detached)
+	g.calls[key] = call
+	g.fetches.Add(1)
+	return call, true
+}
+
+func (call *inflightFetch) addWaiter(detached bool) {
+	if detached {
+		call.detached = true
+		return
+	}
+	call.waiters++
+}
+
+// leave dipanggil oleh waiter yang berhenti menunggu (hasil sudah ada, deadline lewat, atau client
+// terputus). Waiter terakhir membatalkan panggilan yang belum selesai dan melepasnya dari grup,
+// sehingga request berikutnya memulai panggilan baru alih-alih bergabung dengan yang dibatalkan.
+func (g *fetchGroup) leave(call *inflightFetch) {
+	g.mu.Lock()
+	call.waiters--
+	abandoned := call.waiters == 0 && !call.detached
+	if abandoned && g.calls[call.key] == call {
+		delete(g.calls, call.key)
+	}
+	g.mu.Unlock()
+
+	if abandoned {
+		call.cancel()
+	}
+}
+
+func (g *fetchGroup) finish(call *inflightFetch, result providerResult) {
+	call.result = result
+	g.mu.Lock()
+	if g.calls[call.key] == call {
+		delete(g.calls, call.key)
+	}
+	g.mu.Unlock()
+	close(call.done)
+}
+
+// timedOut membuat outcome untuk waiter yang berhenti menunggu sebelum panggilan selesai.
+func (call *inflightFetch) timedOut(index int, name string, err error) providerResult {
+	return providerResult{index: index, outcome: domain.ProviderOutcome{
+		Name:      name,
+		Status:    domain.ProviderStatusTimeout,
+		LatencyMs: time.Since(call.start).Milliseconds(),
+		Attempts:  attemptsMade(call.stats),
+		Error:     sanitizeError(err),
+		Warnings:  call.stats.Warnings(),
+	}}
+}
+
+// providerFetch menjalankan (atau bergabung dengan) panggilan ke provider ke-index dan menyimpan
+// hasilnya ke cache. Pemanggil wajib memanggil a.fetches.leave(call) setelah berhenti menunggu;
+// panggilan baru dibatalkan setelah semua waiter pergi, jadi client yang terputus tetap
+// menghentikan fan-out selama tidak ada request lain yang masih menunggu hasil yang sama.
+func (a *Aggregator) providerFetch(ctx context.Context, index int, criteria domain.SearchCriteria) (*inflightFetch, bool) {
+	return a.startFetch(ctx, index, criteria, false)
+}
+
+// startFetch memulai panggilan dengan context yang lepas dari pembatalan ctx; pembatalannya diatur
+// oleh jumlah waiter. Pemanggil detached tidak dihitung sebagai waiter dan membuat panggilan
+// berjalan sampai FetchTimeout.
+func (a *Aggregator) startFetch(ctx context.Context, index int, criteria domain.SearchCriteria, detached bool) (*inflightFetch, bool) {
+	p := a.Providers[index]
+	key := providerCacheKey(p.Name(), criteria)
+
+	storeCtx, stats := providers.WithCallStats(context.WithoutCancel(ctx))
+	callCtx, cancel := context.WithCancel(storeCtx)
+	call, leader := a.fetches.join(key, stats, cancel, detached)
+	if !leader {
+		cancel()
+		return call, false
+	}
+
+	go func() {
+		defer cancel()
+		fetchCtx, stop := context.WithTimeout(callCtx, FetchTimeout)
+		defer stop()
+
+		res, hedged, err := a.searchProvider(fetchCtx, p, criteria)
+		r := newProviderResult(index, p.Name(), res, err, time.Since(call.start), stats)
+		r.outcome.Hedged = hedged
+
+		a.storeProviderResult(storeCtx, criteria, r)
+		a.fetches.finish(call, r)
+	}()
+
+	return call, true
+}
+
+func (a *Aggregator) CoalescingStats() CoalescingStats {
+	return CoalescingStats{
+		Fetches:   a.fetches.fetches.Load(),
+		Coalesced: a.fetches.coalesced.Load(),
+	}
+}
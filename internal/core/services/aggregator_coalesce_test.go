@@ -0,0 +1,161 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingProvider menahan Search sampai release ditutup atau context-nya dibatalkan; setiap
+// pembatalan dilaporkan ke cancelled (buffered) tanpa memblokir.
+func blockingProvider(release <-chan struct{}, cancelled chan<- struct{}) *stubProvider {
+	return &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		select {
+		case <-release:
+			return nil, nil
+		case <-ctx.Done():
+			select {
+			case cancelled <- struct{}{}:
+			default:
+			}
+			return nil, ctx.Err()
+		}
+	}}
+}
+
+var coalesceCriteria = domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", CabinClass: "economy"}
+
+func fetchAsync(a *Aggregator, ctx context.Context) <-chan []providerResult {
+	out := make(chan []providerResult, 1)
+	go func() {
+		res, _ := a.fetchInParallel(ctx, []fetchTarget{{index: 0, criteria: coalesceCriteria}})
+		out <- res
+	}()
+	return out
+}
+
+func waitForWaiters(t *testing.T, a *Aggregator, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		a.fetches.mu.Lock()
+		waiters := 0
+		for _, call := range a.fetches.calls {
+			waiters += call.waiters
+		}
+		a.fetches.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters", n)
+}
+
+func TestConcurrentFetchesShareOneProviderCall(t *testing.T) {
+	release := make(chan struct{})
+	p := blockingProvider(release, make(chan struct{}, 1))
+	a := newTestAggregator(p)
+
+	first := fetchAsync(a, context.Background())
+	second := fetchAsync(a, context.Background())
+	waitForWaiters(t, a, 2)
+	close(release)
+
+	for _, ch := range []<-chan []providerResult{first, second} {
+		if res := <-ch; res[0].outcome.Status != domain.ProviderStatusOK {
+			t.Errorf("outcome = %+v, want ok", res[0].outcome)
+		}
+	}
+	if p.calls.Load() != 1 {
+		t.Errorf("provider called %d times, want 1", p.calls.Load())
+	}
+	if stats := a.CoalescingStats(); stats.Fetches != 1 || stats.Coalesced != 1 {
+		t.Errorf("stats = %+v, want 1 fetch and 1 coalesced", stats)
+	}
+}
+
+func TestLastWaiterLeavingCancelsProviderCall(t *testing.T) {
+	cancelled := make(chan struct{}, 1)
+	p := blockingProvider(make(chan struct{}), cancelled)
+	a := newTestAggregator(p)
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	first := fetchAsync(a, ctx1)
+	second := fetchAsync(a, ctx2)
+	waitForWaiters(t, a, 2)
+
+	cancel1()
+	<-first
+	select {
+	case <-cancelled:
+		t.Fatal("provider call cancelled while another waiter was still waiting")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	start := time.Now()
+	cancel2()
+	<-second
+	select {
+	case <-cancelled:
+	case <-time.After(FetchTimeout / 2):
+		t.Fatal("provider call kept running after every waiter left")
+	}
+	if elapsed := time.Since(start); elapsed >= FetchTimeout/2 {
+		t.Errorf("cancellation took %v", elapsed)
+	}
+
+	// Request baru setelah pembatalan memulai panggilan baru, bukan bergabung dengan yang dibatalkan.
+	ctx3, cancel3 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel3()
+	a.fetchInParallel(ctx3, []fetchTarget{{index: 0, criteria: coalesceCriteria}})
+	if p.calls.Load() != 2 {
+		t.Errorf("provider called %d times, want a fresh call after cancellation", p.calls.Load())
+	}
+}
+
+func TestDetachedRefreshSurvivesWaiterLeaving(t *testing.T) {
+	release := make(chan struct{})
+	cancelled := make(chan struct{}, 1)
+	a := newTestAggregator(blockingProvider(release, cancelled))
+
+	a.refreshInBackground(0, coalesceCriteria)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := fetchAsync(a, ctx)
+	waitForWaiters(t, a, 1)
+	cancel()
+	<-done
+
+	select {
+	case <-cancelled:
+		t.Fatal("background refresh was cancelled by a departing client")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+}
+
+func TestDetachedJoinIsNotCountedAsCoalesced(t *testing.T) {
+	release := make(chan struct{})
+	p := blockingProvider(release, make(chan struct{}, 1))
+	a := newTestAggregator(p)
+
+	first := fetchAsync(a, context.Background())
+	waitForWaiters(t, a, 1)
+	a.refreshInBackground(0, coalesceCriteria)
+	if stats := a.CoalescingStats(); stats.Fetches != 1 || stats.Coalesced != 0 {
+		t.Errorf("after background join stats = %+v, want 1 fetch and 0 coalesced", stats)
+	}
+
+	second := fetchAsync(a, context.Background())
+	waitForWaiters(t, a, 2)
+	close(release)
+	<-first
+	<-second
+
+	if stats := a.CoalescingStats(); stats.Fetches != 1 || stats.Coalesced != 1 {
+		t.Errorf("stats = %+v, want 1 fetch and 1 coalesced", stats)
+	}
+}
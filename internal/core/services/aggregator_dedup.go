@@ -0,0 +1,68 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DedupModeMerge = "merge"
+	DedupModeRaw   = "raw"
+)
+
+func flightIdentity(f domain.UnifiedFlight) string {
+	number := strings.ToUpper(strings.ReplaceAll(f.FlightNumber, " ", ""))
+	return fmt.Sprintf("%s|%s|%d", strings.ToUpper(f.Airline.Code), number, f.Departure.Timestamp)
+}
+
+// dedupFlights menggabungkan penerbangan fisik yang sama (kode maskapai, nomor penerbangan,
+// dan waktu keberangkatan sama) dari beberapa provider menjadi satu flight dengan daftar offers.
+// Field tingkat atas mengikuti offer termurah. Offer dengan kursi kurang dari passengers dibuang
+// lebih dulu, sehingga offer termurah yang dipilih selalu bisa dipesan untuk semua penumpang.
+func dedupFlights(flights []domain.UnifiedFlight, passengers int) ([]domain.UnifiedFlight, int) {
+	index := map[string]int{}
+	res := make([]domain.UnifiedFlight, 0, len(flights))
+	merged := 0
+
+	for _, f := range flights {
+		if passengers > 0 && f.AvailableSeats < passengers {
+			continue
+		}
+
+		offer := domain.Offer{
+			Provider:       f.Provider,
+			FlightID:       f.ID,
+			Price:          f.Price,
+			AvailableSeats: f.AvailableSeats,
+			Baggage:        f.Baggage,
+		}
+
+		key := flightIdentity(f)
+		i, ok := index[key]
+		if !ok {
+			f.Offers = []domain.Offer{offer}
+			index[key] = len(res)
+			res = append(res, f)
+			continue
+		}
+
+		merged++
+		existing := &res[i]
+		offers := append(existing.Offers, offer)
+		if f.Price.Amount < existing.Price.Amount {
+			*existing = f
+		}
+		existing.Offers = offers
+	}
+
+	for i := range res {
+		offers := res[i].Offers
+		sort.SliceStable(offers, func(a, b int) bool {
+			return offers[a].Price.Amount < offers[b].Price.Amount
+		})
+	}
+
+	return res, merged
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestDedupFlightsSkipsOffersWithoutEnoughSeats(t *testing.T) {
+	dep := time.Date(2025, 12, 15, 8, 0, 0, 0, time.UTC)
+	cheap := stubFlight("GA400", "CGK", "DPS", dep, 110, 800000)
+	cheap.Provider, cheap.AvailableSeats = "Garuda Indonesia", 2
+	pricey := stubFlight("GA400", "CGK", "DPS", dep, 110, 950000)
+	pricey.Provider, pricey.AvailableSeats = "Lion Air", 6
+
+	res, merged := dedupFlights([]domain.UnifiedFlight{cheap, pricey}, 4)
+	if len(res) != 1 || merged != 0 {
+		t.Fatalf("got %d flights, %d merged; want 1 flight, 0 merged", len(res), merged)
+	}
+	if f := res[0]; f.Provider != "Lion Air" || f.AvailableSeats != 6 || len(f.Offers) != 1 {
+		t.Errorf("flight = %s with %d seats and %d offers, want Lion Air offer only", f.Provider, f.AvailableSeats, len(f.Offers))
+	}
+
+	res, merged = dedupFlights([]domain.UnifiedFlight{cheap, pricey}, 1)
+	if len(res) != 1 || merged != 1 || res[0].Provider != "Garuda Indonesia" || len(res[0].Offers) != 2 {
+		t.Errorf("with 1 passenger got %+v, want merged flight led by the cheapest offer", res)
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/core/filterexpr"
+	"cmp"
+	"slices"
+)
+
+type timeBucket struct {
+	name     string
+	from, to string
+	// start dan end dalam menit sejak tengah malam; end eksklusif dan boleh lebih kecil dari start
+	// untuk bucket yang melewati tengah malam.
+	start, end int
+}
+
+var departureBuckets = []timeBucket{
+	{name: "morning", from: "05:00", to: "11:59", start: 5 * 60, end: 12 * 60},
+	{name: "afternoon", from: "12:00", to: "16:59", start: 12 * 60, end: 17 * 60},
+	{name: "evening", from: "17:00", to: "20:59", start: 17 * 60, end: 21 * 60},
+	{name: "night", from: "21:00", to: "04:59", start: 21 * 60, end: 5 * 60},
+}
+
+func (b timeBucket) contains(minutes int) bool {
+	if b.start < b.end {
+		return minutes >= b.start && minutes < b.end
+	}
+	return minutes >= b.start || minutes < b.end
+}
+
+// unfilteredCriteria hanya mempertahankan rute, tanggal, kelas kabin dan jumlah penumpang
+// sehingga facet mencerminkan semua opsi sebelum filter pengguna diterapkan.
+func unfilteredCriteria(c domain.SearchCriteria) domain.SearchCriteria {
+	c.Filters = domain.FilterOptions{}
+	return c
+}
+
+func computeFacets(flights []domain.UnifiedFlight) *domain.Facets {
+	facets := &domain.Facets{
+		Total:          len(flights),
+		Airlines:       []domain.AirlineFacet{},
+		Stops:          []domain.StopsFacet{},
+		DepartureTimes: make([]domain.TimeBucketFacet, len(departureBuckets)),
+		Amenities:      []domain.AmenityFacet{},
+	}
+	for i, b := range departureBuckets {
+		facets.DepartureTimes[i] = domain.TimeBucketFacet{Bucket: b.name, From: b.from, To: b.to}
+	}
+
+	airlines := map[string]int{}
+	stops := map[int]int{}
+	amenities := map[string]int{}
+
+	for _, f := range flights {
+		price := f.Price.Amount
+
+		if i, ok := airlines[f.Airline.Name]; ok {
+			facets.Airlines[i].Count++
+			facets.Airlines[i].MinPrice = min(facets.Airlines[i].MinPrice, price)
+		} else {
+			airlines[f.Airline.Name] = len(facets.Airlines)
+			facets.Airlines = append(facets.Airlines, domain.AirlineFacet{Name: f.Airline.Name, Code: f.Airline.Code, Count: 1, MinPrice: price})
+		}
+
+		if i, ok := stops[f.Stops]; ok {
+			facets.Stops[i].Count++
+			facets.Stops[i].MinPrice = min(facets.Stops[i].MinPrice, price)
+		} else {
+			stops[f.Stops] = len(facets.Stops)
+			facets.Stops = append(facets.Stops, domain.StopsFacet{Stops: f.Stops, Count: 1, MinPrice: price})
+		}
+
+		if facets.PriceRange == nil {
+			facets.PriceRange = &domain.PriceRangeFacet{Min: price, Max: price, Currency: f.Price.Currency}
+		} else {
+			facets.PriceRange.Min = min(facets.PriceRange.Min, price)
+			facets.PriceRange.Max = max(facets.PriceRange.Max, price)
+		}
+
+		dur := f.Duration.TotalMinutes
+		if facets.DurationRange == nil {
+			facets.DurationRange = &domain.DurationFacet{MinMinutes: dur, MaxMinutes: dur}
+		} else {
+			facets.DurationRange.MinMinutes = min(facets.DurationRange.MinMinutes, dur)
+			facets.DurationRange.MaxMinutes = max(facets.DurationRange.MaxMinutes, dur)
+		}
+
+		depMinutes := f.Departure.TimeOfDay.Hour()*60 + f.Departure.TimeOfDay.Minute()
+		for i, b := range departureBuckets {
+			if !b.contains(depMinutes) {
+				continue
+			}
+			bucket := &facets.DepartureTimes[i]
+			bucket.Count++
+			if bucket.MinPrice == nil || price < *bucket.MinPrice {
+				p := price
+				bucket.MinPrice = &p
+			}
+			break
+		}
+
+		seen := map[string]struct{}{}
+		for _, raw := range f.Amenities {
+			a := filterexpr.NormalizeAmenity(raw)
+			if _, dup := seen[a]; dup {
+				continue
+			}
+			seen[a] = struct{}{}
+			if i, ok := amenities[a]; ok {
+				facets.Amenities[i].Count++
+			} else {
+				amenities[a] = len(facets.Amenities)
+				facets.Amenities = append(facets.Amenities, domain.AmenityFacet{Amenity: a, Count: 1})
+			}
+		}
+	}
+
+	slices.SortFunc(facets.Airlines, func(a, b domain.AirlineFacet) int { return cmp.Compare(a.Name, b.Name) })
+	slices.SortFunc(facets.Stops, func(a, b domain.StopsFacet) int { return cmp.Compare(a.Stops, b.Stops) })
+	slices.SortFunc(facets.Amenities, func(a, b domain.AmenityFacet) int { return cmp.Compare(a.Amenity, b.Amenity) })
+
+	return facets
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDepartureTimeBucketBoundaries(t *testing.T) {
+	tests := []struct {
+		clock  string
+		bucket string
+	}{
+		{"00:00", "night"},
+		{"04:59", "night"},
+		{"05:00", "morning"},
+		{"11:59", "morning"},
+		{"12:00", "afternoon"},
+		{"16:59", "afternoon"},
+		{"17:00", "evening"},
+		{"20:59", "evening"},
+		{"21:00", "night"},
+		{"23:59", "night"},
+	}
+
+	for _, tt := range tests {
+		dep, _ := time.Parse("2006-01-02 15:04", "2025-12-15 "+tt.clock)
+		facets := computeFacets([]domain.UnifiedFlight{stubFlight("F1", "CGK", "DPS", dep, 90, 500000)})
+		for _, b := range facets.DepartureTimes {
+			want := 0
+			if b.Bucket == tt.bucket {
+				want = 1
+			}
+			if b.Count != want {
+				t.Errorf("%s: bucket %s count = %d, want %d", tt.clock, b.Bucket, b.Count, want)
+			}
+		}
+	}
+}
+
+func TestComputeFacets(t *testing.T) {
+	day := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	a := stubFlight("F1", "CGK", "DPS", day.Add(5*time.Hour), 90, 700000)
+	a.Amenities = []string{"WiFi", "wifi", "Meal"}
+	b := stubFlight("F2", "CGK", "DPS", day.Add(11*time.Hour+59*time.Minute), 150, 500000)
+	b.Stops = 1
+	b.Amenities = []string{"meal"}
+	c := stubFlight("F3", "CGK", "DPS", day.Add(21*time.Hour), 120, 900000)
+	c.Airline = domain.AirlineInfo{Name: "Garuda Indonesia", Code: "GA"}
+
+	f := computeFacets([]domain.UnifiedFlight{a, b, c})
+
+	if f.Total != 3 || f.PriceRange.Min != 500000 || f.PriceRange.Max != 900000 || f.PriceRange.Currency != "IDR" {
+		t.Errorf("total %d, price range %+v", f.Total, *f.PriceRange)
+	}
+	if f.DurationRange.MinMinutes != 90 || f.DurationRange.MaxMinutes != 150 {
+		t.Errorf("duration range = %+v, want 90-150", *f.DurationRange)
+	}
+
+	wantBuckets := map[string]struct {
+		count    int
+		minPrice float64
+	}{
+		"morning":   {2, 500000},
+		"afternoon": {0, 0},
+		"evening":   {0, 0},
+		"night":     {1, 900000},
+	}
+	for _, bucket := range f.DepartureTimes {
+		want := wantBuckets[bucket.Bucket]
+		if bucket.Count != want.count || (want.count == 0) != (bucket.MinPrice == nil) || (bucket.MinPrice != nil && *bucket.MinPrice != want.minPrice) {
+			t.Errorf("bucket %s = %+v, want count %d min %v", bucket.Bucket, bucket, want.count, want.minPrice)
+		}
+	}
+
+	if len(f.Airlines) != 2 || f.Airlines[0].Name != "Garuda Indonesia" || f.Airlines[1].Count != 2 || f.Airlines[1].MinPrice != 500000 {
+		t.Errorf("airlines = %+v", f.Airlines)
+	}
+	if len(f.Stops) != 2 || f.Stops[0] != (domain.StopsFacet{Stops: 0, Count: 2, MinPrice: 700000}) || f.Stops[1] != (domain.StopsFacet{Stops: 1, Count: 1, MinPrice: 500000}) {
+		t.Errorf("stops = %+v", f.Stops)
+	}
+	// Amenity dinormalisasi dan dihitung sekali per penerbangan.
+	if len(f.Amenities) != 2 || f.Amenities[0] != (domain.AmenityFacet{Amenity: "meal", Count: 2}) || f.Amenities[1] != (domain.AmenityFacet{Amenity: "wifi", Count: 1}) {
+		t.Errorf("amenities = %+v", f.Amenities)
+	}
+}
+
+func TestFacetCountsAfterFiltering(t *testing.T) {
+	p := &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		return []domain.UnifiedFlight{
+			stubFlight("F1", "CGK", "DPS", day.Add(6*time.Hour), 90, 400000),
+			stubFlight("F2", "CGK", "DPS", day.Add(12*time.Hour), 90, 500000),
+			stubFlight("F3", "CGK", "DPS", day.Add(17*time.Hour), 90, 500001),
+			stubFlight("F4", "CGK", "DPS", day.Add(22*time.Hour), 90, 800000),
+		}, nil
+	}}
+	a := newTestAggregator(p)
+
+	tests := []struct {
+		name     string
+		filters  domain.FilterOptions
+		total    int
+		buckets  map[string]int
+		maxPrice float64
+	}{
+		{"max price is inclusive", domain.FilterOptions{MaxPrice: float(500000)}, 2, map[string]int{"morning": 1, "afternoon": 1}, 500000},
+		{"min price is inclusive", domain.FilterOptions{MinPrice: float(500001)}, 2, map[string]int{"evening": 1, "night": 1}, 800000},
+		{"departure window", domain.FilterOptions{MinDepTime: text("12:00"), MaxDepTime: text("17:00")}, 2, map[string]int{"afternoon": 1, "evening": 1}, 500001},
+		{"nothing matches", domain.FilterOptions{MaxPrice: float(100000)}, 0, map[string]int{}, 0},
+	}
+
+	for _, tt := range tests {
+		resp := a.SearchFlights(context.Background(), domain.SearchCriteria{
+			Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy",
+			Filters: tt.filters, IncludeFilteredFacets: true,
+		})
+
+		if resp.Facets.Total != 4 {
+			t.Errorf("%s: unfiltered total = %d, want 4", tt.name, resp.Facets.Total)
+		}
+		f := resp.FilteredFacets
+		if f.Total != tt.total || len(resp.Flights) != tt.total {
+			t.Errorf("%s: filtered total = %d with %d flights, want %d", tt.name, f.Total, len(resp.Flights), tt.total)
+		}
+		for _, b := range f.DepartureTimes {
+			if b.Count != tt.buckets[b.Bucket] {
+				t.Errorf("%s: bucket %s count = %d, want %d", tt.name, b.Bucket, b.Count, tt.buckets[b.Bucket])
+			}
+		}
+		if tt.total == 0 {
+			if f.PriceRange != nil {
+				t.Errorf("%s: price range = %+v, want none", tt.name, *f.PriceRange)
+			}
+		} else if f.PriceRange.Max != tt.maxPrice {
+			t.Errorf("%s: price max = %v, want %v", tt.name, f.PriceRange.Max, tt.maxPrice)
+		}
+	}
+}
@@ -3,53 +3,145 @@ package services
 import (
 	"bookcabin-test/internal/core/domain"
 	"bookcabin-test/internal/platform/providers"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
 	"log"
-	"sync"
+	"net/url"
+	"strings"
+	"time"
 )
 
-func (a *Aggregator) fetchInParallel(criteria domain.SearchCriteria) ([]domain.UnifiedFlight, int) {
-	var wg sync.WaitGroup
-	resultsChan := make(chan []domain.UnifiedFlight, len(a.Providers))
-	statusChan := make(chan bool, len(a.Providers))
-
-	for _, p := range a.Providers {
-		wg.Add(1)
-		go func(provider providers.ProviderInterface) {
-			defer wg.Done()
-			res, err := provider.Search(criteria)
-
-			if err != nil {
-				log.Printf("worker goroutine failed for %s: %v", provider.Name(), err)
-				statusChan <- false
-				return
-			}
-
-			statusChan <- true
-			var validFlights []domain.UnifiedFlight
-			for _, f := range res {
-				if f.IsValid {
-					validFlights = append(validFlights, f)
-				}
-			}
-			resultsChan <- validFlights
-		}(p)
-	}
-
-	wg.Wait()
-	close(resultsChan)
-	close(statusChan)
-
-	var allFlights []domain.UnifiedFlight
-	for res := range resultsChan {
-		allFlights = append(allFlights, res...)
-	}
-
-	successCount := 0
-	for s := range statusChan {
-		if s {
-			successCount++
+const maxErrorMessageLength = 200
+
+type providerResult struct {
+	index   int
+	flights []domain.UnifiedFlight
+	outcome domain.ProviderOutcome
+}
+
+// fetchInParallel memanggil semua targets (provider + rute) secara paralel dan mengembalikan apa
+// yang sudah tiba sebelum FetchTimeout, berurutan sesuai targets; provider yang terlambat dilaporkan
+// timeout. Nilai bool menandakan setidaknya satu panggilan bergabung dengan panggilan yang sedang berjalan.
+func (a *Aggregator) fetchInParallel(ctx context.Context, targets []fetchTarget) ([]providerResult, bool) {
+	ctx, cancel := context.WithTimeout(ctx, FetchTimeout)
+	defer cancel()
+
+	calls := make([]*inflightFetch, len(targets))
+	coalesced := false
+	for j, target := range targets {
+		call, leader := a.providerFetch(ctx, target.index, target.criteria)
+		calls[j] = call
+		coalesced = coalesced || !leader
+	}
+	defer func() {
+		for _, call := range calls {
+			a.fetches.leave(call)
+		}
+	}()
+
+	results := make([]providerResult, len(targets))
+	late := 0
+	for j, call := range calls {
+		select {
+		case <-call.done:
+			results[j] = call.result
+			continue
+		case <-ctx.Done():
+		}
+
+		// Panggilan yang selesai bersamaan dengan deadline tetap dipakai hasilnya.
+		select {
+		case <-call.done:
+			results[j] = call.result
+		default:
+			index := targets[j].index
+			results[j] = call.timedOut(index, a.Providers[index].Name(), ctx.Err())
+			late++
 		}
 	}
 
-	return allFlights, successCount
+	if late > 0 {
+		log.Printf("fetch deadline reached, %d provider(s) timed out: %v", late, ctx.Err())
+	}
+	return results, coalesced
+}
+
+func newProviderResult(index int, name string, res []domain.UnifiedFlight, err error, latency time.Duration, stats *providers.CallStats) providerResult {
+	outcome := domain.ProviderOutcome{
+		Name:      name,
+		Status:    domain.ProviderStatusOK,
+		LatencyMs: latency.Milliseconds(),
+		Attempts:  attemptsMade(stats),
+		Warnings:  stats.Warnings(),
+	}
+
+	if err != nil {
+		if !errors.Is(err, providers.ErrCircuitOpen) {
+			log.Printf("worker goroutine failed for %s: %v", name, err)
+		}
+		outcome.Status = domain.ProviderStatusError
+		switch {
+		case errors.Is(err, providers.ErrCircuitOpen):
+			outcome.Status = domain.ProviderStatusCircuitOpen
+			outcome.Attempts = 0
+		case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+			outcome.Status = domain.ProviderStatusTimeout
+		}
+		outcome.Error = sanitizeError(err)
+		return providerResult{index: index, outcome: outcome}
+	}
+
+	outcome.ResultCount = len(res)
+	var validFlights []domain.UnifiedFlight
+	for _, f := range res {
+		if f.IsValid {
+			validFlights = append(validFlights, f)
+		} else {
+			outcome.InvalidCount++
+		}
+	}
+
+	return providerResult{index: index, flights: validFlights, outcome: outcome}
+}
+
+func attemptsMade(stats *providers.CallStats) int {
+	if n := stats.Attempts(); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// sanitizeError mengubah error provider menjadi pesan yang aman ditampilkan ke client,
+// tanpa path file atau detail internal lainnya.
+func sanitizeError(err error) string {
+	var pathErr *fs.PathError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var urlErr *url.Error
+
+	switch {
+	case errors.Is(err, providers.ErrCircuitOpen):
+		return "provider temporarily disabled by circuit breaker"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "provider did not respond before the deadline"
+	case errors.Is(err, context.Canceled):
+		return "request was cancelled"
+	case errors.As(err, &pathErr):
+		return "provider data source unavailable"
+	case errors.As(err, &urlErr):
+		if urlErr.Timeout() {
+			return "provider did not respond before the deadline"
+		}
+		return "provider unreachable"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "provider returned a malformed response"
+	}
+
+	msg := strings.Join(strings.Fields(err.Error()), " ")
+	if len(msg) > maxErrorMessageLength {
+		msg = msg[:maxErrorMessageLength] + "..."
+	}
+	return msg
 }
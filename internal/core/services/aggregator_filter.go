@@ -2,9 +2,18 @@ package services
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/core/filterexpr"
+	"bookcabin-test/internal/platform/airports"
 	"time"
 )
 
+const (
+	DefaultNearbyRadiusKm = 100.0
+	MaxNearbyRadiusKm     = 500.0
+	// MaxExpandedAirports membatasi jumlah bandara per sisi rute setelah ekspansi metro/nearby.
+	MaxExpandedAirports = 4
+)
+
 func timeOnly(t time.Time) time.Time {
 	return time.Date(0, 1, 1, t.Hour(), t.Minute(), 0, 0, time.UTC)
 }
@@ -29,9 +38,73 @@ func parseFilterTime(s *string) *time.Time {
 	return &tOnly
 }
 
+// airportCodes mengembalikan bandara konkret untuk code (metro dan, jika diminta, bandara terdekat),
+// dibatasi MaxExpandedAirports agar jumlah rute yang di-fetch ke provider tetap kecil.
+func airportCodes(code string, opts domain.SearchCriteria) []string {
+	radius := DefaultNearbyRadiusKm
+	if opts.NearbyRadiusKm != nil {
+		radius = *opts.NearbyRadiusKm
+	}
+
+	codes := airports.Expand(code, opts.IncludeNearbyAirports, radius)
+	if len(codes) > MaxExpandedAirports {
+		codes = codes[:MaxExpandedAirports]
+	}
+	return codes
+}
+
+func expandAirports(code string, opts domain.SearchCriteria) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, c := range airportCodes(code, opts) {
+		set[c] = struct{}{}
+	}
+	return set
+}
+
+func isExpanded(set map[string]struct{}, code string) bool {
+	_, exact := set[code]
+	return len(set) > 1 || !exact
+}
+
+func violatesLayoverRules(layovers []domain.Layover, opts domain.FilterOptions, excluded map[string]struct{}) bool {
+	for _, l := range layovers {
+		if outOfRangeInt(l.DurationMinutes, opts.MinConnectionMinutes, opts.MaxLayoverMinutes) {
+			return true
+		}
+		if _, ok := excluded[l.Airport]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNothing menggantikan ekspresi yang gagal di-compile sehingga filter gagal tertutup:
+// tidak ada penerbangan yang lolos, bukan semua.
+type matchNothing struct{}
+
+func (matchNothing) Match(*domain.UnifiedFlight) bool { return false }
+
+// compileFilters meng-compile Expression sekali untuk seluruh pencarian jika handler belum
+// melakukannya.
+func compileFilters(filters domain.FilterOptions) domain.FilterOptions {
+	if filters.Expression == "" || filters.Compiled != nil {
+		return filters
+	}
+	if program, err := filterexpr.Compile(filters.Expression); err == nil {
+		filters.Compiled = program
+	} else {
+		filters.Compiled = matchNothing{}
+	}
+	return filters
+}
+
 func filterFlights(flights []domain.UnifiedFlight, opts domain.SearchCriteria) []domain.UnifiedFlight {
 	var res []domain.UnifiedFlight
 
+	origins := expandAirports(opts.Origin, opts)
+	destinations := expandAirports(opts.Destination, opts)
+	expanded := isExpanded(origins, opts.Origin) || isExpanded(destinations, opts.Destination)
+
 	minDepTime := parseFilterTime(opts.Filters.MinDepTime)
 	maxDepTime := parseFilterTime(opts.Filters.MaxDepTime)
 	minArrTime := parseFilterTime(opts.Filters.MinArrTime)
@@ -42,8 +115,18 @@ func filterFlights(flights []domain.UnifiedFlight, opts domain.SearchCriteria) [
 		allowedAirlines[name] = struct{}{}
 	}
 
+	excludedLayovers := map[string]struct{}{}
+	for _, code := range opts.Filters.ExcludedLayoverAirports {
+		excludedLayovers[code] = struct{}{}
+	}
+
+	expression := compileFilters(opts.Filters).Compiled
+
 	for _, f := range flights {
-		if f.Departure.Airport != opts.Origin || f.Arrival.Airport != opts.Destination {
+		if _, ok := origins[f.Departure.Airport]; !ok {
+			continue
+		}
+		if _, ok := destinations[f.Arrival.Airport]; !ok {
 			continue
 		}
 		if opts.CabinClass != "" && f.CabinClass != opts.CabinClass {
@@ -62,7 +145,12 @@ func filterFlights(flights []domain.UnifiedFlight, opts domain.SearchCriteria) [
 			continue
 		}
 
-		if len(allowedAirlines) > 0 {
+		if violatesLayoverRules(f.Layovers, opts.Filters, excludedLayovers) {
+			continue
+		}
+
+		// Filter maskapai untuk self-transfer sudah diterapkan per leg saat itinerary dibangun.
+		if len(allowedAirlines) > 0 && !f.SelfTransfer {
 			if _, ok := allowedAirlines[f.Airline.Name]; !ok {
 				continue
 			}
@@ -78,6 +166,17 @@ func filterFlights(flights []domain.UnifiedFlight, opts domain.SearchCriteria) [
 			continue
 		}
 
+		if expression != nil && !expression.Match(&f) {
+			continue
+		}
+
+		if expanded {
+			f.MatchedAirports = &domain.AirportMatch{
+				Origin:      f.Departure.Airport,
+				Destination: f.Arrival.Airport,
+			}
+		}
+
 		res = append(res, f)
 	}
 
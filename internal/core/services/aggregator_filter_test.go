@@ -0,0 +1,65 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingMatcher struct {
+	calls atomic.Int32
+}
+
+func (m *countingMatcher) Match(*domain.UnifiedFlight) bool {
+	m.calls.Add(1)
+	return true
+}
+
+func expressionTestAggregator() *Aggregator {
+	return newTestAggregator(&stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		return []domain.UnifiedFlight{
+			stubFlight("SA100", c.Origin, c.Destination, day.Add(8*time.Hour), 110, 900000),
+			stubFlight("SA200", c.Origin, c.Destination, day.Add(14*time.Hour), 110, 1600000),
+		}, nil
+	}})
+}
+
+func TestSearchFlightsFilterExpression(t *testing.T) {
+	a := expressionTestAggregator()
+	criteria := domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15"}
+
+	criteria.Filters.Expression = `price < 1000000`
+	if resp := a.SearchFlights(context.Background(), criteria); len(resp.Flights) != 1 || resp.Flights[0].ID != "SA100" {
+		t.Fatalf("valid expression: got %d flights", len(resp.Flights))
+	}
+
+	criteria.Filters.Expression = `price <`
+	if resp := a.SearchFlights(context.Background(), criteria); len(resp.Flights) != 0 {
+		t.Errorf("invalid expression must match nothing, got %d flights", len(resp.Flights))
+	}
+}
+
+func TestSearchFlightsReusesCompiledExpression(t *testing.T) {
+	a := expressionTestAggregator()
+	matcher := &countingMatcher{}
+	returnDate := "2025-12-20"
+	criteria := domain.SearchCriteria{
+		Origin:        "CGK",
+		Destination:   "DPS",
+		DepartureDate: "2025-12-15",
+		ReturnDate:    &returnDate,
+		// Expression tidak valid: jika di-compile ulang, tidak ada penerbangan yang lolos.
+		Filters: domain.FilterOptions{Expression: `price <`, Compiled: matcher},
+	}
+
+	resp := a.SearchFlights(context.Background(), criteria)
+	if len(resp.RoundTrips) == 0 {
+		t.Fatal("want round trips built with the compiled expression")
+	}
+	if got := matcher.calls.Load(); got != 4 {
+		t.Errorf("matcher called %d times, want once per outbound and inbound flight (4)", got)
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+func (a *Aggregator) SearchFlexible(ctx context.Context, criteria domain.FlexibleSearchCriteria) domain.PriceCalendarResponse {
+	start := time.Now()
+	criteria.Filters = compileFilters(criteria.Filters)
+
+	center, err := time.Parse(dateLayout, criteria.DepartureDate)
+	if err != nil {
+		return domain.PriceCalendarResponse{SearchCriteria: criteria}
+	}
+
+	days := make([]domain.DaySummary, 2*criteria.WindowDays+1)
+
+	var wg sync.WaitGroup
+	for i := range days {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			dayCriteria := criteria.SearchCriteria
+			dayCriteria.DepartureDate = center.AddDate(0, 0, i-criteria.WindowDays).Format(dateLayout)
+			dayCriteria.ReturnDate = nil
+			dayCriteria.ReturnFilters = nil
+
+			resp := a.SearchFlights(ctx, dayCriteria)
+			days[i] = summarizeDay(dayCriteria.DepartureDate, resp)
+		}(i)
+	}
+	wg.Wait()
+
+	var cheapestDate string
+	var cheapestPrice float64
+	for _, d := range days {
+		if d.Cheapest != nil && (cheapestDate == "" || d.Cheapest.Price.Amount < cheapestPrice) {
+			cheapestDate = d.Date
+			cheapestPrice = d.Cheapest.Price.Amount
+		}
+	}
+
+	return domain.PriceCalendarResponse{
+		SearchCriteria: criteria,
+		Days:           days,
+		CheapestDate:   cheapestDate,
+		SearchTimeMs:   time.Since(start).Milliseconds(),
+	}
+}
+
+func summarizeDay(date string, resp domain.SearchResponse) domain.DaySummary {
+	summary := domain.DaySummary{
+		Date:        date,
+		OptionCount: len(resp.Flights),
+		Providers:   []string{},
+		CacheHit:    resp.Metadata.CacheHit,
+	}
+
+	seen := map[string]struct{}{}
+	for i := range resp.Flights {
+		f := &resp.Flights[i]
+		if summary.Cheapest == nil || f.Price.Amount < summary.Cheapest.Price.Amount {
+			summary.Cheapest = f
+		}
+		if summary.Fastest == nil || f.Duration.TotalMinutes < summary.Fastest.Duration.TotalMinutes {
+			summary.Fastest = f
+		}
+		if _, ok := seen[f.Provider]; !ok {
+			seen[f.Provider] = struct{}{}
+			summary.Providers = append(summary.Providers, f.Provider)
+		}
+	}
+	sort.Strings(summary.Providers)
+
+	return summary
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearchFlexibleSummarizesEachDay(t *testing.T) {
+	alpha := &stubProvider{name: "Alpha", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		if c.DepartureDate == "2025-12-16" {
+			return nil, errors.New("upstream unavailable")
+		}
+		slow := stubFlight("AL1-"+c.DepartureDate, c.Origin, c.Destination, day.Add(7*time.Hour), 180, 600000)
+		fast := stubFlight("AL2-"+c.DepartureDate, c.Origin, c.Destination, day.Add(9*time.Hour), 100, 950000)
+		slow.Provider, fast.Provider = "Alpha", "Alpha"
+		return []domain.UnifiedFlight{slow, fast}, nil
+	}}
+	beta := &stubProvider{name: "Beta", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		price := 700000.0
+		if c.DepartureDate == "2025-12-14" {
+			price = 500000
+		}
+		f := stubFlight("BE1-"+c.DepartureDate, c.Origin, c.Destination, day.Add(12*time.Hour), 120, price)
+		f.Provider = "Beta"
+		return []domain.UnifiedFlight{f}, nil
+	}}
+	a := newTestAggregator(alpha, beta)
+
+	resp := a.SearchFlexible(context.Background(), domain.FlexibleSearchCriteria{
+		SearchCriteria: domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15"},
+		WindowDays:     1,
+	})
+
+	tests := []struct {
+		date      string
+		count     int
+		cheapest  string
+		fastest   string
+		providers []string
+	}{
+		{"2025-12-14", 3, "BE1-2025-12-14", "AL2-2025-12-14", []string{"Alpha", "Beta"}},
+		{"2025-12-15", 3, "AL1-2025-12-15", "AL2-2025-12-15", []string{"Alpha", "Beta"}},
+		// Alpha gagal: hari tetap diringkas dari provider yang berhasil.
+		{"2025-12-16", 1, "BE1-2025-12-16", "BE1-2025-12-16", []string{"Beta"}},
+	}
+
+	if len(resp.Days) != len(tests) {
+		t.Fatalf("got %d days, want %d", len(resp.Days), len(tests))
+	}
+	for i, tt := range tests {
+		d := resp.Days[i]
+		if d.Date != tt.date || d.OptionCount != tt.count {
+			t.Errorf("day %d = %s with %d options, want %s with %d", i, d.Date, d.OptionCount, tt.date, tt.count)
+			continue
+		}
+		if d.Cheapest == nil || d.Cheapest.ID != tt.cheapest {
+			t.Errorf("%s cheapest = %v, want %s", tt.date, d.Cheapest, tt.cheapest)
+		}
+		if d.Fastest == nil || d.Fastest.ID != tt.fastest {
+			t.Errorf("%s fastest = %v, want %s", tt.date, d.Fastest, tt.fastest)
+		}
+		if !slices.Equal(d.Providers, tt.providers) {
+			t.Errorf("%s providers = %v, want %v", tt.date, d.Providers, tt.providers)
+		}
+	}
+
+	if resp.CheapestDate != "2025-12-14" {
+		t.Errorf("cheapest date = %s, want 2025-12-14", resp.CheapestDate)
+	}
+}
+
+func TestSearchFlexibleDayWithoutResults(t *testing.T) {
+	p := &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		return nil, errors.New("upstream unavailable")
+	}}
+	a := newTestAggregator(p)
+
+	resp := a.SearchFlexible(context.Background(), domain.FlexibleSearchCriteria{
+		SearchCriteria: domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15"},
+	})
+
+	if len(resp.Days) != 1 {
+		t.Fatalf("got %d days, want 1", len(resp.Days))
+	}
+	d := resp.Days[0]
+	if d.OptionCount != 0 || d.Cheapest != nil || d.Fastest != nil || len(d.Providers) != 0 {
+		t.Errorf("failed day summary = %+v, want empty", d)
+	}
+	if resp.CheapestDate != "" {
+		t.Errorf("cheapest date = %q, want empty", resp.CheapestDate)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type HedgeConfig struct {
+	Enabled bool
+	// Percentile latensi (mis. 0.9 untuk p90) yang dipakai sebagai ambang pengiriman request kedua.
+	Percentile float64
+	MinSamples int
+	WindowSize int
+	MinDelay   time.Duration
+}
+
+func DefaultHedgeConfig() HedgeConfig {
+	return HedgeConfig{
+		Enabled:    false,
+		Percentile: 0.9,
+		MinSamples: 20,
+		WindowSize: 200,
+		MinDelay:   10 * time.Millisecond,
+	}
+}
+
+// latencyHistogram menyimpan latensi sukses terakhir dari satu provider dalam ring buffer.
+type latencyHistogram struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	pos     int
+	size    int
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.samples) < h.size {
+		h.samples = append(h.samples, d)
+		return
+	}
+	h.samples[h.pos] = d
+	h.pos = (h.pos + 1) % h.size
+}
+
+func (h *latencyHistogram) percentile(p float64, minSamples int) (time.Duration, bool) {
+	h.mu.Lock()
+	sorted := append([]time.Duration(nil), h.samples...)
+	h.mu.Unlock()
+
+	if len(sorted) == 0 || len(sorted) < minSamples {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(p*float64(len(sorted))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx], true
+}
+
+func (a *Aggregator) latencyHistogram(provider string) *latencyHistogram {
+	size := a.Hedging.WindowSize
+	if size < 1 {
+		size = 1
+	}
+	h, _ := a.latencies.LoadOrStore(provider, &latencyHistogram{size: size})
+	return h.(*latencyHistogram)
+}
+
+type hedgeAttempt struct {
+	flights []domain.UnifiedFlight
+	err     error
+	started time.Time
+}
+
+// searchProvider memanggil provider, dan jika hedging aktif serta provider belum menjawab
+// dalam p90 latensinya sendiri, mengirim request kedua yang identik. Jawaban sukses pertama
+// dipakai dan request lainnya dibatalkan.
+func (a *Aggregator) searchProvider(ctx context.Context, p providers.ProviderInterface, criteria domain.SearchCriteria) ([]domain.UnifiedFlight, bool, error) {
+	hist := a.latencyHistogram(p.Name())
+
+	threshold, ok := hist.percentile(a.Hedging.Percentile, a.Hedging.MinSamples)
+	if !a.Hedging.Enabled || !ok {
+		start := time.Now()
+		res, err := p.Search(ctx, criteria)
+		if err == nil {
+			hist.observe(time.Since(start))
+		}
+		return res, false, err
+	}
+	if threshold < a.Hedging.MinDelay {
+		threshold = a.Hedging.MinDelay
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make(chan hedgeAttempt, 2)
+	launch := func() {
+		go func() {
+			start := time.Now()
+			res, err := p.Search(ctx, criteria)
+			attempts <- hedgeAttempt{flights: res, err: err, started: start}
+		}()
+	}
+
+	launch()
+	inFlight := 1
+	hedged := false
+
+	timer := time.NewTimer(threshold)
+	defer timer.Stop()
+
+	for {
+		select {
+		case r := <-attempts:
+			inFlight--
+			if r.err == nil {
+				hist.observe(time.Since(r.started))
+				return r.flights, hedged, nil
+			}
+			if inFlight == 0 {
+				return nil, hedged, r.err
+			}
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				inFlight++
+				launch()
+			}
+		}
+	}
+}
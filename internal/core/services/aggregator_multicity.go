@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMinConnectionMinutes = 60
+	MaxMultiCityResults         = 100
+	maxCandidatesPerLeg         = 20
+	// multiCityBeamWidth adalah jumlah jalur parsial yang dipertahankan setelah setiap leg.
+	multiCityBeamWidth = 2 * MaxMultiCityResults
+)
+
+func (a *Aggregator) SearchMultiCity(ctx context.Context, criteria domain.MultiCitySearchCriteria) domain.MultiCitySearchResponse {
+	start := time.Now()
+	criteria.Filters = compileFilters(criteria.Filters)
+	legs := make([]domain.SearchResponse, len(criteria.Legs))
+
+	var wg sync.WaitGroup
+	for i, leg := range criteria.Legs {
+		wg.Add(1)
+		go func(i int, leg domain.LegCriteria) {
+			defer wg.Done()
+			legs[i] = a.searchOneWay(ctx, domain.SearchCriteria{
+				Origin:         leg.Origin,
+				Destination:    leg.Destination,
+				DepartureDate:  leg.DepartureDate,
+				Passengers:     criteria.Passengers,
+				CabinClass:     criteria.CabinClass,
+				Filters:        criteria.Filters,
+				SortBy:         "best_value",
+				ScoringProfile: criteria.ScoringProfile,
+				ScoringWeights: criteria.ScoringWeights,
+			})
+		}(i, leg)
+	}
+	wg.Wait()
+
+	minConnection := DefaultMinConnectionMinutes
+	if criteria.MinConnectionMinutes != nil {
+		minConnection = *criteria.MinConnectionMinutes
+	}
+
+	candidates := make([][]domain.UnifiedFlight, len(legs))
+	for i, leg := range legs {
+		candidates[i] = leg.Flights
+		if len(candidates[i]) > maxCandidatesPerLeg {
+			candidates[i] = candidates[i][:maxCandidatesPerLeg]
+		}
+	}
+
+	itineraries := combineLegs(candidates, int64(minConnection)*60, sortSpecFor(criteria.SortBy, criteria.Sort))
+	if len(itineraries) > MaxMultiCityResults {
+		itineraries = itineraries[:MaxMultiCityResults]
+	}
+
+	return domain.MultiCitySearchResponse{
+		SearchCriteria: criteria,
+		Legs:           legs,
+		Itineraries:    itineraries,
+		SearchTimeMs:   time.Since(start).Milliseconds(),
+	}
+}
+
+// combineLegs menyusun itinerary leg demi leg dengan syarat jeda antara kedatangan satu leg dan
+// keberangkatan leg berikutnya minimal minConnectionSecs. Setelah setiap leg hanya multiCityBeamWidth
+// jalur parsial terbaik (menurut spec) yang diteruskan, sehingga jumlah kombinasi tidak tumbuh
+// eksponensial terhadap jumlah leg. Hasil sudah terurut menurut spec.
+func combineLegs(candidates [][]domain.UnifiedFlight, minConnectionSecs int64, spec []domain.SortKey) []domain.MultiCityItinerary {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var paths []domain.MultiCityItinerary
+	for leg, flights := range candidates {
+		var next []domain.MultiCityItinerary
+		if leg == 0 {
+			for _, f := range flights {
+				next = append(next, newMultiCityItinerary([]domain.UnifiedFlight{f}))
+			}
+		}
+		for _, p := range paths {
+			last := p.Flights[len(p.Flights)-1]
+			for _, f := range flights {
+				// Leg berikutnya harus berangkat setelah leg sebelumnya tiba, berapa pun minConnectionSecs.
+				gap := f.Departure.Timestamp - last.Arrival.Timestamp
+				if gap <= 0 || gap < minConnectionSecs {
+					continue
+				}
+				next = append(next, newMultiCityItinerary(append(p.Flights[:len(p.Flights):len(p.Flights)], f)))
+			}
+		}
+
+		next = sortItineraries(next, spec)
+		if len(next) > multiCityBeamWidth {
+			next = next[:multiCityBeamWidth]
+		}
+		paths = next
+	}
+
+	return paths
+}
+
+func newMultiCityItinerary(path []domain.UnifiedFlight) domain.MultiCityItinerary {
+	flights := make([]domain.UnifiedFlight, len(path))
+	copy(flights, path)
+
+	ids := make([]string, len(flights))
+	var totalPrice, score float64
+	var totalMinutes int
+	for i, f := range flights {
+		ids[i] = f.ID
+		totalPrice += f.Price.Amount
+		totalMinutes += f.Duration.TotalMinutes
+		score += f.Score
+	}
+
+	return domain.MultiCityItinerary{
+		ID:      strings.Join(ids, "+"),
+		Flights: flights,
+		TotalPrice: domain.PriceInfo{
+			Amount:          totalPrice,
+			FormattedAmount: providers.FormatIDR(totalPrice),
+			Currency:        flights[0].Price.Currency,
+		},
+		TotalDuration: domain.DurationInfo{
+			TotalMinutes: totalMinutes,
+			Formatted:    formatDuration(totalMinutes),
+		},
+		Score: score,
+	}
+}
+
+func sortItineraries(its []domain.MultiCityItinerary, spec []domain.SortKey) []domain.MultiCityItinerary {
+	sortStable(its, spec, func(it *domain.MultiCityItinerary) sortMetrics {
+		m := sortMetrics{
+			score:     it.Score,
+			price:     it.TotalPrice.Amount,
+			duration:  it.TotalDuration.TotalMinutes,
+			departure: it.Flights[0].Departure.Timestamp,
+			arrival:   it.Flights[len(it.Flights)-1].Arrival.Timestamp,
+			id:        it.ID,
+		}
+		airlines := make([]string, len(it.Flights))
+		for i, f := range it.Flights {
+			m.stops += f.Stops
+			airlines[i] = f.Airline.Name
+		}
+		m.airline = strings.Join(airlines, "/")
+		return m
+	})
+	return its
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSearchMultiCitySixLegsIsPruned(t *testing.T) {
+	// Setiap rute punya 30 penerbangan per hari, sehingga tanpa pruning 6 leg menghasilkan 20^6 kombinasi.
+	p := &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, err := time.Parse("2006-01-02", c.DepartureDate)
+		if err != nil {
+			return nil, err
+		}
+		var res []domain.UnifiedFlight
+		for i := range 30 {
+			id := fmt.Sprintf("%s%s-%s-%02d", c.Origin, c.Destination, c.DepartureDate, i)
+			res = append(res, stubFlight(id, c.Origin, c.Destination, day.Add(time.Duration(6*60+i*20)*time.Minute), 90, float64(500000+(i*7919)%30*10000)))
+		}
+		return res, nil
+	}}
+	a := newTestAggregator(p)
+
+	route := []string{"CGK", "DPS", "SUB", "UPG", "KNO", "JOG", "CGK"}
+	criteria := domain.MultiCitySearchCriteria{
+		Passengers: 1,
+		CabinClass: "economy",
+		Sort:       []domain.SortKey{{Key: SortKeyPrice, Direction: SortAsc}},
+	}
+	for i := range 6 {
+		criteria.Legs = append(criteria.Legs, domain.LegCriteria{
+			Origin:        route[i],
+			Destination:   route[i+1],
+			DepartureDate: fmt.Sprintf("2025-12-%02d", 15+i),
+		})
+	}
+
+	start := time.Now()
+	resp := a.SearchMultiCity(context.Background(), criteria)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("6-leg search took %v", elapsed)
+	}
+
+	if len(resp.Itineraries) == 0 || len(resp.Itineraries) > MaxMultiCityResults {
+		t.Fatalf("got %d itineraries, want 1..%d", len(resp.Itineraries), MaxMultiCityResults)
+	}
+
+	var cheapest float64
+	for _, leg := range resp.Legs {
+		cheapest += leg.Flights[0].Price.Amount
+	}
+	if got := resp.Itineraries[0].TotalPrice.Amount; got != cheapest {
+		t.Errorf("cheapest itinerary = %v, want %v", got, cheapest)
+	}
+
+	for i, it := range resp.Itineraries {
+		if len(it.Flights) != 6 {
+			t.Fatalf("itinerary %d has %d flights", i, len(it.Flights))
+		}
+		if i > 0 && it.TotalPrice.Amount < resp.Itineraries[i-1].TotalPrice.Amount {
+			t.Fatalf("itineraries not sorted by price at %d", i)
+		}
+	}
+}
+
+func TestCombineLegsEnforcesMinConnection(t *testing.T) {
+	day := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	first := []domain.UnifiedFlight{stubFlight("A1", "CGK", "DPS", day.Add(8*time.Hour), 120, 100)}
+	second := []domain.UnifiedFlight{
+		stubFlight("B1", "DPS", "SUB", day.Add(10*time.Hour+30*time.Minute), 60, 100),
+		stubFlight("B2", "DPS", "SUB", day.Add(11*time.Hour), 60, 200),
+	}
+
+	res := combineLegs([][]domain.UnifiedFlight{first, second}, 60*60, []domain.SortKey{{Key: SortKeyPrice, Direction: SortAsc}})
+	if len(res) != 1 || res[0].ID != "A1+B2" {
+		t.Fatalf("got %+v, want only A1+B2", res)
+	}
+}
+
+func TestCombineLegsRejectsOverlappingLegs(t *testing.T) {
+	day := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	first := []domain.UnifiedFlight{stubFlight("A1", "CGK", "DPS", day.Add(8*time.Hour), 120, 100)}
+	second := []domain.UnifiedFlight{
+		stubFlight("B1", "DPS", "SUB", day.Add(7*time.Hour), 60, 100),
+		stubFlight("B2", "DPS", "SUB", day.Add(10*time.Hour), 60, 150),
+		stubFlight("B3", "DPS", "SUB", day.Add(10*time.Hour+5*time.Minute), 60, 200),
+	}
+
+	// Jeda minimum negatif tidak boleh meloloskan leg yang berangkat sebelum leg sebelumnya tiba.
+	res := combineLegs([][]domain.UnifiedFlight{first, second}, -600*60, []domain.SortKey{{Key: SortKeyPrice, Direction: SortAsc}})
+	if len(res) != 1 || res[0].ID != "A1+B3" {
+		t.Fatalf("got %d itineraries, want only A1+B3", len(res))
+	}
+}
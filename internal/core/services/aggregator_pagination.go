@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	MaxPageLimit = 100
+	// SnapshotTTL adalah berapa lama snapshot hasil pencarian disimpan untuk halaman berikutnya.
+	SnapshotTTL       = 10 * time.Minute
+	snapshotKeyPrefix = "snapshot:"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrCursorExpired = errors.New("cursor has expired")
+)
+
+// pageCursor adalah isi cursor opaque: key snapshot di cache, waktu snapshot dibuat, spesifikasi
+// sort yang dipakai saat snapshot dibuat, dan posisi awal halaman berikutnya.
+type pageCursor struct {
+	Key     string           `json:"k"`
+	Created int64            `json:"t"`
+	Sort    []domain.SortKey `json:"s"`
+	Offset  int              `json:"o"`
+	Limit   int              `json:"l"`
+}
+
+func (c pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Key == "" || c.Offset < 0 || c.Limit <= 0 {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// SearchFirstPage menjalankan pencarian one-way, menyimpan seluruh hasil yang sudah diurutkan
+// sebagai snapshot, lalu mengembalikan halaman pertama. Halaman berikutnya dilayani NextPage
+// dari snapshot yang sama sehingga tetap konsisten walaupun cache provider diperbarui.
+// Key snapshot diturunkan dari kriteria dan sort, jadi request halaman pertama yang sama memakai
+// ulang snapshot selama entry provider yang membentuknya masih fresh; setelah itu pencarian
+// berjalan lagi lewat cache provider (termasuk stale-while-revalidate) dan snapshot diganti.
+func (a *Aggregator) SearchFirstPage(ctx context.Context, criteria domain.SearchCriteria) domain.SearchResponse {
+	start := time.Now()
+	criteria.Filters = compileFilters(criteria.Filters)
+	criteria.Sort = sortSpecFor(criteria.SortBy, criteria.Sort)
+	cursor := pageCursor{
+		Key:   snapshotKey(criteria),
+		Sort:  criteria.Sort,
+		Limit: criteria.Limit,
+	}
+
+	if cached, ok := a.FlightCache.Load(ctx, cursor.Key); ok && cached.Snapshot != nil && start.Before(cached.ReuseUntil) {
+		cursor.Created = cached.Timestamp.UnixNano()
+		resp := page(agedSnapshot(*cached.Snapshot, time.Since(cached.Timestamp).Milliseconds()), cursor)
+		resp.Metadata.SearchTimeMs = time.Since(start).Milliseconds()
+		return resp
+	}
+
+	resp := a.searchOneWay(ctx, criteria)
+	if len(resp.Flights) > cursor.Limit {
+		now := time.Now()
+		cursor.Created = now.UnixNano()
+		a.FlightCache.Store(ctx, cursor.Key, CachedResponse{
+			Snapshot:   &resp,
+			Timestamp:  now,
+			TTL:        SnapshotTTL,
+			ReuseUntil: a.snapshotReuseUntil(resp.Metadata, now),
+		}, SnapshotTTL)
+	}
+
+	return page(resp, cursor)
+}
+
+// snapshotReuseUntil menghitung kapan entry provider tertua yang membentuk snapshot berhenti
+// fresh. Snapshot dengan provider gagal tidak dipakai ulang agar provider itu dicoba lagi.
+func (a *Aggregator) snapshotReuseUntil(metadata domain.ResponseMetadata, now time.Time) time.Time {
+	if metadata.ProvidersFailed > 0 {
+		return time.Time{}
+	}
+
+	var until time.Time
+	for _, o := range metadata.Providers {
+		fresh := now.Add(a.cacheTTL(o.Name) - time.Duration(o.CacheAgeMs)*time.Millisecond)
+		if until.IsZero() || fresh.Before(until) {
+			until = fresh
+		}
+	}
+	return until
+}
+
+// agedSnapshot menyalin snapshot dengan metadata umur yang diperbarui: semua provider kini
+// dilayani dari cache dan umurnya bertambah sejak snapshot dibuat.
+func agedSnapshot(resp domain.SearchResponse, ageMs int64) domain.SearchResponse {
+	resp.Metadata.Providers = slices.Clone(resp.Metadata.Providers)
+	resp.Metadata.ProvidersCached = 0
+	for i, o := range resp.Metadata.Providers {
+		if isSuccess(o.Status) {
+			resp.Metadata.Providers[i] = cachedOutcome(o, o.CacheAgeMs+ageMs)
+			resp.Metadata.ProvidersCached++
+		}
+	}
+	resp.Metadata.CacheHit = true
+	resp.Metadata.Coalesced = false
+	resp.Metadata.DataAgeMs += ageMs
+	return resp
+}
+
+// NextPage mengembalikan halaman dari snapshot yang dirujuk cursor. limit 0 memakai limit
+// dari cursor sebelumnya.
+func (a *Aggregator) NextPage(ctx context.Context, cursorValue string, limit int) (domain.SearchResponse, error) {
+	start := time.Now()
+
+	cursor, err := decodeCursor(cursorValue)
+	if err != nil {
+		return domain.SearchResponse{}, err
+	}
+	if limit > 0 {
+		cursor.Limit = limit
+	}
+
+	// Snapshot yang sudah diganti pencarian halaman pertama berikutnya dianggap kedaluwarsa.
+	cached, ok := a.FlightCache.Load(ctx, cursor.Key)
+	if !ok || cached.Snapshot == nil || cached.Timestamp.UnixNano() != cursor.Created {
+		return domain.SearchResponse{}, ErrCursorExpired
+	}
+	if !slices.Equal(cached.Snapshot.SearchCriteria.Sort, cursor.Sort) {
+		return domain.SearchResponse{}, ErrInvalidCursor
+	}
+
+	resp := page(agedSnapshot(*cached.Snapshot, time.Since(cached.Timestamp).Milliseconds()), cursor)
+	resp.Metadata.SearchTimeMs = time.Since(start).Milliseconds()
+	return resp, nil
+}
+
+// page memotong resp.Flights mulai dari cursor.Offset sebanyak cursor.Limit dan mengisi
+// NextCursor jika masih ada hasil. Metadata.TotalResults tetap jumlah seluruh hasil.
+func page(resp domain.SearchResponse, cursor pageCursor) domain.SearchResponse {
+	total := len(resp.Flights)
+	from := min(cursor.Offset, total)
+	to := min(from+cursor.Limit, total)
+
+	resp.Flights = resp.Flights[from:to]
+	resp.SearchCriteria.Limit = cursor.Limit
+	resp.SearchCriteria.Cursor = ""
+	resp.SearchCriteria.Sort = cursor.Sort
+
+	if to < total {
+		next := cursor
+		next.Offset = to
+		resp.NextCursor = next.encode()
+	}
+	return resp
+}
+
+// snapshotKey membentuk key snapshot dari kriteria pencarian (termasuk sort, tanpa limit dan
+// cursor yang tidak mengubah isi snapshot).
+func snapshotKey(criteria domain.SearchCriteria) string {
+	criteria.Limit = 0
+	criteria.Cursor = ""
+	data, _ := json.Marshal(criteria)
+	sum := sha256.Sum256(data)
+	return snapshotKeyPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorEncodeDecode(t *testing.T) {
+	want := pageCursor{Key: "snapshot:abc", Created: 42, Sort: []domain.SortKey{{Key: SortKeyPrice, Direction: SortAsc}}, Offset: 20, Limit: 10}
+	got, err := decodeCursor(want.encode())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Key != want.Key || got.Created != want.Created || got.Offset != want.Offset || got.Limit != want.Limit || len(got.Sort) != 1 || got.Sort[0] != want.Sort[0] {
+		t.Errorf("decoded = %+v, want %+v", got, want)
+	}
+
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	invalid := map[string]string{
+		"not base64":      "%%%",
+		"not json":        encode("hello"),
+		"missing key":     encode(`{"o":0,"l":10}`),
+		"negative offset": encode(`{"k":"snapshot:x","o":-1,"l":10}`),
+		"zero limit":      encode(`{"k":"snapshot:x","o":0,"l":0}`),
+	}
+	for name, cursor := range invalid {
+		if _, err := decodeCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func paginationAggregator(n int) (*Aggregator, *stubProvider) {
+	p := &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		var res []domain.UnifiedFlight
+		for i := range n {
+			// Harga sengaja banyak yang sama untuk menguji tie-breaker yang stabil.
+			res = append(res, stubFlight(fmt.Sprintf("SA%03d", i), c.Origin, c.Destination, day.Add(time.Duration(i)*10*time.Minute), 90, float64(500000+(i%3)*100000)))
+		}
+		return res, nil
+	}}
+	return newTestAggregator(p), p
+}
+
+var paginationCriteria = domain.SearchCriteria{
+	Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy",
+	SortBy: "price_asc", Limit: 10,
+}
+
+func TestPaginationWalksTheWholeSnapshot(t *testing.T) {
+	a, _ := paginationAggregator(25)
+	ctx := context.Background()
+
+	full := a.SearchFlights(ctx, paginationCriteria)
+	resp := a.SearchFirstPage(ctx, paginationCriteria)
+
+	var ids []string
+	for {
+		for _, f := range resp.Flights {
+			ids = append(ids, f.ID)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		var err error
+		if resp, err = a.NextPage(ctx, resp.NextCursor, 0); err != nil {
+			t.Fatalf("next page: %v", err)
+		}
+		if resp.Metadata.TotalResults != 25 {
+			t.Errorf("total_results = %d, want 25", resp.Metadata.TotalResults)
+		}
+	}
+
+	if len(ids) != len(full.Flights) {
+		t.Fatalf("paged %d flights, want %d", len(ids), len(full.Flights))
+	}
+	for i, f := range full.Flights {
+		if ids[i] != f.ID {
+			t.Fatalf("flight %d = %s, want %s", i, ids[i], f.ID)
+		}
+	}
+}
+
+func TestRepeatedFirstPageReusesSnapshot(t *testing.T) {
+	a, p := paginationAggregator(25)
+	ctx := context.Background()
+
+	first := a.SearchFirstPage(ctx, paginationCriteria)
+	time.Sleep(5 * time.Millisecond)
+	for range 5 {
+		again := a.SearchFirstPage(ctx, paginationCriteria)
+		if again.NextCursor != first.NextCursor {
+			t.Fatalf("cursor changed between identical first-page requests")
+		}
+		m := again.Metadata
+		if !m.CacheHit || m.ProvidersCached != 1 || m.Providers[0].Status != domain.ProviderStatusCached || m.DataAgeMs < 5 || m.Providers[0].CacheAgeMs != m.DataAgeMs {
+			t.Fatalf("reused page metadata = %+v, want a cached provider aged since the snapshot", m)
+		}
+	}
+	if got := p.calls.Load(); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+	smaller := paginationCriteria
+	smaller.Limit = 5
+	a.SearchFirstPage(ctx, smaller)
+
+	if n := countSnapshots(a); n != 1 {
+		t.Errorf("cache holds %d snapshots, want 1", n)
+	}
+
+	other := paginationCriteria
+	other.SortBy = "duration_asc"
+	a.SearchFirstPage(ctx, other)
+	if n := countSnapshots(a); n != 2 {
+		t.Errorf("cache holds %d snapshots after a different sort, want 2", n)
+	}
+}
+
+func TestFirstPageSnapshotReuseEndsWithProviderTTL(t *testing.T) {
+	a, p := paginationAggregator(25)
+	a.CacheTTLs["Stub"] = 50 * time.Millisecond
+	a.StaleGrace = time.Minute
+	ctx := context.Background()
+
+	first := a.SearchFirstPage(ctx, paginationCriteria)
+	cursor, _ := decodeCursor(first.NextCursor)
+	snapshot, _ := a.FlightCache.Load(ctx, cursor.Key)
+	if until := snapshot.ReuseUntil.Sub(snapshot.Timestamp); until > 50*time.Millisecond {
+		t.Errorf("snapshot reusable for %v, want at most the 50ms provider TTL", until)
+	}
+
+	// Lewat TTL provider: halaman pertama melewati cache provider dan dilayani stale-while-revalidate.
+	time.Sleep(70 * time.Millisecond)
+	again := a.SearchFirstPage(ctx, paginationCriteria)
+	if !again.Metadata.Stale || again.Metadata.DataAgeMs < 50 {
+		t.Errorf("metadata = %+v, want stale data at least 50ms old", again.Metadata)
+	}
+	if again.NextCursor == first.NextCursor {
+		t.Error("cursor unchanged after the snapshot was replaced")
+	}
+	if _, err := a.NextPage(ctx, first.NextCursor, 0); !errors.Is(err, ErrCursorExpired) {
+		t.Errorf("err = %v, want ErrCursorExpired for a replaced snapshot", err)
+	}
+	waitForCalls(t, p, 2)
+}
+
+func TestFirstPageWithFailedProviderIsNotReused(t *testing.T) {
+	a, p := paginationAggregator(25)
+	down := &stubProvider{name: "Down", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		return nil, errors.New("unavailable")
+	}}
+	a.Providers = append(a.Providers, down)
+	ctx := context.Background()
+
+	a.SearchFirstPage(ctx, paginationCriteria)
+	a.SearchFirstPage(ctx, paginationCriteria)
+	if p.calls.Load() != 1 || down.calls.Load() < 2 {
+		t.Errorf("calls: stub %d, down %d; want the failed provider retried", p.calls.Load(), down.calls.Load())
+	}
+}
+
+func countSnapshots(a *Aggregator) int {
+	lru := a.FlightCache.(*LRUCache)
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	n := 0
+	for key := range lru.items {
+		if strings.HasPrefix(key, snapshotKeyPrefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNextPageExpiredSnapshot(t *testing.T) {
+	a, _ := paginationAggregator(25)
+	ctx := context.Background()
+
+	resp := a.SearchFirstPage(ctx, paginationCriteria)
+	cursor, _ := decodeCursor(resp.NextCursor)
+	a.FlightCache.Delete(ctx, cursor.Key)
+
+	if _, err := a.NextPage(ctx, resp.NextCursor, 0); !errors.Is(err, ErrCursorExpired) {
+		t.Errorf("err = %v, want ErrCursorExpired", err)
+	}
+}
+
+func TestNextPageSortMismatch(t *testing.T) {
+	a, _ := paginationAggregator(25)
+	ctx := context.Background()
+
+	resp := a.SearchFirstPage(ctx, paginationCriteria)
+	cursor, _ := decodeCursor(resp.NextCursor)
+	cursor.Sort = []domain.SortKey{{Key: SortKeyDuration, Direction: SortAsc}}
+
+	if _, err := a.NextPage(ctx, cursor.encode(), 0); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("err = %v, want ErrInvalidCursor for a tampered sort", err)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"fmt"
+	"sync"
+)
+
+const MaxRoundTripResults = 100
+
+func (a *Aggregator) searchRoundTrip(ctx context.Context, criteria domain.SearchCriteria) domain.SearchResponse {
+	outboundCriteria := criteria
+	outboundCriteria.ReturnDate = nil
+	outboundCriteria.ReturnFilters = nil
+
+	var outbound, inbound domain.SearchResponse
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		outbound = a.searchOneWay(ctx, outboundCriteria)
+	}()
+	go func() {
+		defer wg.Done()
+		inbound = a.searchOneWay(ctx, returnLegCriteria(criteria))
+	}()
+	wg.Wait()
+
+	roundTrips := pairRoundTrips(outbound.Flights, inbound.Flights)
+	roundTrips = sortRoundTrips(roundTrips, sortSpecFor(criteria.SortBy, criteria.Sort))
+	if len(roundTrips) > MaxRoundTripResults {
+		roundTrips = roundTrips[:MaxRoundTripResults]
+	}
+
+	metadata := outbound.Metadata
+	metadata.TotalResults = len(roundTrips)
+	returnMetadata := inbound.Metadata
+
+	return domain.SearchResponse{
+		SearchCriteria: criteria,
+		Metadata:       metadata,
+		ReturnMetadata: &returnMetadata,
+		Facets:         outbound.Facets,
+		FilteredFacets: outbound.FilteredFacets,
+		ReturnFacets:   inbound.Facets,
+		Flights:        outbound.Flights,
+		ReturnFlights:  inbound.Flights,
+		RoundTrips:     roundTrips,
+	}
+}
+
+// returnLegCriteria membalik rute dan memakai ReturnDate sebagai tanggal keberangkatan.
+// Filter yang sama tetap berlaku, kecuali jendela waktu yang di-override oleh ReturnFilters.
+func returnLegCriteria(c domain.SearchCriteria) domain.SearchCriteria {
+	rc := c
+	rc.Origin = c.Destination
+	rc.Destination = c.Origin
+	rc.DepartureDate = *c.ReturnDate
+	rc.ReturnDate = nil
+	rc.ReturnFilters = nil
+
+	if w := c.ReturnFilters; w != nil {
+		rc.Filters.MinDepTime = w.MinDepTime
+		rc.Filters.MaxDepTime = w.MaxDepTime
+		rc.Filters.MinArrTime = w.MinArrTime
+		rc.Filters.MaxArrTime = w.MaxArrTime
+	}
+	return rc
+}
+
+// pairRoundTrips memasangkan penerbangan pergi dan pulang. Seperti multi-city dan self-transfer,
+// setiap leg dibatasi maxCandidatesPerLeg teratas (input sudah terurut) sebelum dipasangkan.
+func pairRoundTrips(outbound, inbound []domain.UnifiedFlight) []domain.RoundTripItinerary {
+	if len(outbound) > maxCandidatesPerLeg {
+		outbound = outbound[:maxCandidatesPerLeg]
+	}
+	if len(inbound) > maxCandidatesPerLeg {
+		inbound = inbound[:maxCandidatesPerLeg]
+	}
+
+	var res []domain.RoundTripItinerary
+	for _, out := range outbound {
+		for _, in := range inbound {
+			if in.Departure.Timestamp <= out.Arrival.Timestamp {
+				continue
+			}
+
+			totalPrice := out.Price.Amount + in.Price.Amount
+			totalMinutes := out.Duration.TotalMinutes + in.Duration.TotalMinutes
+
+			res = append(res, domain.RoundTripItinerary{
+				ID:       out.ID + "+" + in.ID,
+				Outbound: out,
+				Inbound:  in,
+				TotalPrice: domain.PriceInfo{
+					Amount:          totalPrice,
+					FormattedAmount: providers.FormatIDR(totalPrice),
+					Currency:        out.Price.Currency,
+				},
+				TotalDuration: domain.DurationInfo{
+					TotalMinutes: totalMinutes,
+					Formatted:    formatDuration(totalMinutes),
+				},
+				Score: out.Score + in.Score,
+			})
+		}
+	}
+	return res
+}
+
+func sortRoundTrips(trips []domain.RoundTripItinerary, spec []domain.SortKey) []domain.RoundTripItinerary {
+	sortStable(trips, spec, func(t *domain.RoundTripItinerary) sortMetrics {
+		return sortMetrics{
+			score:     t.Score,
+			price:     t.TotalPrice.Amount,
+			duration:  t.TotalDuration.TotalMinutes,
+			departure: t.Outbound.Departure.Timestamp,
+			arrival:   t.Inbound.Arrival.Timestamp,
+			stops:     t.Outbound.Stops + t.Inbound.Stops,
+			airline:   t.Outbound.Airline.Name + "/" + t.Inbound.Airline.Name,
+			id:        t.ID,
+		}
+	})
+	return trips
+}
+
+func formatDuration(minutes int) string {
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
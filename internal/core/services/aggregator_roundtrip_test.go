@@ -0,0 +1,52 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSearchRoundTripPairing(t *testing.T) {
+	p := &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		if c.Origin == "CGK" {
+			return []domain.UnifiedFlight{
+				stubFlight("OUT-AM", "CGK", "DPS", day.Add(8*time.Hour), 110, 900000),
+				stubFlight("OUT-PM", "CGK", "DPS", day.Add(19*time.Hour), 110, 800000),
+			}, nil
+		}
+		return []domain.UnifiedFlight{stubFlight("RET", "DPS", "CGK", day.Add(20*time.Hour), 120, 700000)}, nil
+	}}
+	a := newTestAggregator(p)
+
+	// Pulang di hari yang sama: OUT-PM tiba 20:50, setelah RET berangkat, sehingga tidak boleh dipasangkan.
+	returnDate := "2025-12-15"
+	resp := a.SearchFlights(context.Background(), domain.SearchCriteria{
+		Origin:        "CGK",
+		Destination:   "DPS",
+		DepartureDate: "2025-12-15",
+		ReturnDate:    &returnDate,
+	})
+
+	if len(resp.Flights) != 2 || len(resp.ReturnFlights) != 1 {
+		t.Fatalf("got %d outbound and %d return flights, want 2 and 1", len(resp.Flights), len(resp.ReturnFlights))
+	}
+	if len(resp.RoundTrips) != 1 {
+		t.Fatalf("got %d round trips, want 1", len(resp.RoundTrips))
+	}
+
+	trip := resp.RoundTrips[0]
+	if trip.ID != "OUT-AM+RET" {
+		t.Errorf("paired %s, want OUT-AM+RET", trip.ID)
+	}
+	if trip.TotalPrice.Amount != 1600000 || trip.TotalPrice.Currency != "IDR" {
+		t.Errorf("total price = %+v, want 1600000 IDR", trip.TotalPrice)
+	}
+	if trip.TotalDuration.TotalMinutes != 230 || trip.TotalDuration.Formatted != "3h 50m" {
+		t.Errorf("total duration = %+v, want 230 minutes", trip.TotalDuration)
+	}
+	if resp.Metadata.TotalResults != 1 || resp.ReturnMetadata == nil {
+		t.Errorf("metadata = %+v, return metadata = %v", resp.Metadata, resp.ReturnMetadata)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"fmt"
+)
+
+// fetchTarget adalah satu panggilan provider untuk satu rute konkret (bandara, bukan kode metro).
+type fetchTarget struct {
+	index    int
+	criteria domain.SearchCriteria
+}
+
+// concreteRoutes memperluas origin dan destination menjadi semua pasangan bandara konkret yang
+// dikirim ke provider. Rute tanpa ekspansi menghasilkan satu criteria yang sama dengan input.
+func concreteRoutes(criteria domain.SearchCriteria) []domain.SearchCriteria {
+	var routes []domain.SearchCriteria
+	for _, origin := range airportCodes(criteria.Origin, criteria) {
+		for _, destination := range airportCodes(criteria.Destination, criteria) {
+			if origin == destination {
+				continue
+			}
+			route := criteria
+			route.Origin = origin
+			route.Destination = destination
+			route.IncludeNearbyAirports = false
+			route.NearbyRadiusKm = nil
+			routes = append(routes, route)
+		}
+	}
+	if len(routes) == 0 {
+		return []domain.SearchCriteria{criteria}
+	}
+	return routes
+}
+
+func (a *Aggregator) fetchTargets(criteria domain.SearchCriteria) []fetchTarget {
+	routes := concreteRoutes(criteria)
+	targets := make([]fetchTarget, 0, len(a.Providers)*len(routes))
+	for i := range a.Providers {
+		for _, route := range routes {
+			targets = append(targets, fetchTarget{index: i, criteria: route})
+		}
+	}
+	return targets
+}
+
+// routeFlights membuang penerbangan yang tidak sesuai pasangan bandara rute yang diminta, mis. dari
+// provider yang mengabaikan query. Tanpa ini, setiap rute hasil ekspansi metro membawa salinan
+// penerbangan yang sama dan dedup melaporkannya sebagai duplikat dari provider yang sama.
+func routeFlights(flights []domain.UnifiedFlight, route domain.SearchCriteria) []domain.UnifiedFlight {
+	res := make([]domain.UnifiedFlight, 0, len(flights))
+	for _, f := range flights {
+		if f.Departure.Airport == route.Origin && f.Arrival.Airport == route.Destination {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func isSuccess(status string) bool {
+	return status == domain.ProviderStatusOK || status == domain.ProviderStatusCached
+}
+
+// mergeRouteOutcomes menggabungkan outcome satu provider dari beberapa rute konkret. Provider
+// dianggap sukses jika minimal satu rute berhasil; rute yang gagal dilaporkan sebagai warning.
+func mergeRouteOutcomes(parts []providerResult, routes []domain.SearchCriteria) domain.ProviderOutcome {
+	if len(parts) == 1 {
+		return parts[0].outcome
+	}
+
+	var merged domain.ProviderOutcome
+	var failed []domain.ProviderOutcome
+	allCached := true
+	for j, part := range parts {
+		o := part.outcome
+		merged.Name = o.Name
+		merged.LatencyMs = max(merged.LatencyMs, o.LatencyMs)
+		merged.CacheAgeMs = max(merged.CacheAgeMs, o.CacheAgeMs)
+		merged.Attempts += o.Attempts
+		merged.ResultCount += o.ResultCount
+		merged.InvalidCount += o.InvalidCount
+		merged.Hedged = merged.Hedged || o.Hedged
+		merged.Warnings = append(merged.Warnings, o.Warnings...)
+
+		allCached = allCached && o.Status == domain.ProviderStatusCached
+		if !isSuccess(o.Status) {
+			failed = append(failed, o)
+			merged.Warnings = append(merged.Warnings, fmt.Sprintf("route %s-%s %s: %s", routes[j].Origin, routes[j].Destination, o.Status, o.Error))
+		}
+	}
+
+	switch {
+	case len(failed) == len(parts):
+		merged.Status = failed[0].Status
+		merged.Error = failed[0].Error
+	case allCached:
+		merged.Status = domain.ProviderStatusCached
+	default:
+		merged.Status = domain.ProviderStatusOK
+	}
+	return merged
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// routeRecorder mencatat rute yang diminta ke provider dan mengembalikan satu penerbangan per rute.
+type routeRecorder struct {
+	mu     sync.Mutex
+	routes []string
+	fail   map[string]bool
+}
+
+func (r *routeRecorder) provider() *stubProvider {
+	return &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		route := c.Origin + "-" + c.Destination
+		r.mu.Lock()
+		r.routes = append(r.routes, route)
+		r.mu.Unlock()
+		if r.fail[route] {
+			return nil, errors.New("upstream unavailable")
+		}
+
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		return []domain.UnifiedFlight{stubFlight(route, c.Origin, c.Destination, day.Add(8*time.Hour), 110, 900000)}, nil
+	}}
+}
+
+func (r *routeRecorder) requested() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := slices.Clone(r.routes)
+	slices.Sort(res)
+	return res
+}
+
+func TestMetroCodeFetchesConcreteAirports(t *testing.T) {
+	rec := &routeRecorder{}
+	a := newTestAggregator(rec.provider())
+	criteria := domain.SearchCriteria{Origin: "JKT", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+
+	resp := a.SearchFlights(context.Background(), criteria)
+	if got, want := rec.requested(), []string{"CGK-DPS", "HLP-DPS"}; !slices.Equal(got, want) {
+		t.Fatalf("requested routes = %v, want %v", got, want)
+	}
+	if len(resp.Flights) != 2 {
+		t.Fatalf("got %d flights, want 2", len(resp.Flights))
+	}
+	for _, f := range resp.Flights {
+		if f.MatchedAirports == nil || f.MatchedAirports.Origin != f.Departure.Airport {
+			t.Errorf("flight %s has matched airports %+v", f.ID, f.MatchedAirports)
+		}
+	}
+	if o := resp.Metadata.Providers[0]; o.Status != domain.ProviderStatusOK || o.ResultCount != 2 {
+		t.Errorf("outcome = %+v, want ok with 2 results", o)
+	}
+
+	resp = a.SearchFlights(context.Background(), criteria)
+	if len(rec.requested()) != 2 {
+		t.Errorf("second search fetched again: %v", rec.requested())
+	}
+	if !resp.Metadata.CacheHit || resp.Metadata.Providers[0].Status != domain.ProviderStatusCached {
+		t.Errorf("second search metadata = %+v, want full cache hit", resp.Metadata)
+	}
+}
+
+func TestNearbyAirportsAreRequested(t *testing.T) {
+	rec := &routeRecorder{}
+	a := newTestAggregator(rec.provider())
+	radius := 50.0
+	criteria := domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy", IncludeNearbyAirports: true, NearbyRadiusKm: &radius}
+
+	a.SearchFlights(context.Background(), criteria)
+	if got := rec.requested(); !slices.Contains(got, "HLP-DPS") || !slices.Contains(got, "CGK-DPS") {
+		t.Fatalf("requested routes = %v, want CGK-DPS and HLP-DPS", got)
+	}
+}
+
+func TestPartialRouteFailureIsReportedAsWarning(t *testing.T) {
+	rec := &routeRecorder{fail: map[string]bool{"HLP-DPS": true}}
+	a := newTestAggregator(rec.provider())
+	criteria := domain.SearchCriteria{Origin: "JKT", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+
+	resp := a.SearchFlights(context.Background(), criteria)
+	o := resp.Metadata.Providers[0]
+	if o.Status != domain.ProviderStatusOK || len(resp.Flights) != 1 {
+		t.Fatalf("outcome = %+v with %d flights, want ok with 1 flight", o, len(resp.Flights))
+	}
+	if len(o.Warnings) != 1 {
+		t.Errorf("warnings = %v, want one warning for HLP-DPS", o.Warnings)
+	}
+}
+
+func TestMetroExpansionIgnoresOffRouteFlights(t *testing.T) {
+	// Provider mengabaikan query dan selalu mengembalikan seluruh jadwalnya.
+	dep := time.Date(2025, 12, 15, 8, 0, 0, 0, time.UTC)
+	p := &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		return []domain.UnifiedFlight{
+			stubFlight("SA100", "CGK", "DPS", dep, 110, 900000),
+			stubFlight("SA200", "HLP", "DPS", dep.Add(2*time.Hour), 110, 850000),
+			stubFlight("SA300", "CGK", "SUB", dep.Add(4*time.Hour), 80, 600000),
+		}, nil
+	}}
+	a := newTestAggregator(p)
+
+	resp := a.SearchFlights(context.Background(), domain.SearchCriteria{Origin: "JKT", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"})
+	if len(resp.Flights) != 2 {
+		t.Fatalf("got %d flights, want 2", len(resp.Flights))
+	}
+	for _, f := range resp.Flights {
+		if len(f.Offers) > 1 {
+			t.Errorf("flight %s merged %d offers from the same provider", f.ID, len(f.Offers))
+		}
+	}
+	if resp.Metadata.DuplicatesMerged != 0 {
+		t.Errorf("duplicates merged = %d, want 0", resp.Metadata.DuplicatesMerged)
+	}
+	if o := resp.Metadata.Providers[0]; o.ResultCount != 2 {
+		t.Errorf("result count = %d, want 2", o.ResultCount)
+	}
+}
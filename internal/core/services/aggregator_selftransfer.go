@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SelfTransferRiskLow    = "low"
+	SelfTransferRiskMedium = "medium"
+	SelfTransferRiskHigh   = "high"
+)
+
+type SelfTransferConfig struct {
+	Hubs          []string
+	MinConnection time.Duration
+	MaxConnection time.Duration
+}
+
+func DefaultSelfTransferConfig() SelfTransferConfig {
+	return SelfTransferConfig{
+		Hubs:          []string{"CGK", "DPS", "SUB", "UPG"},
+		MinConnection: 3 * time.Hour,
+		MaxConnection: 12 * time.Hour,
+	}
+}
+
+// buildSelfTransfers membentuk itinerary virtual origin → hub → destination dari dua tiket
+// one-way yang terpisah. Setiap leg dicari lewat searchOneWay sehingga cache per rute dipakai ulang.
+// Leg kedua juga dicari untuk hari berikutnya agar sambungan yang melewati tengah malam (masih di
+// bawah MaxConnection) ikut terbentuk.
+func (a *Aggregator) buildSelfTransfers(ctx context.Context, criteria domain.SearchCriteria) []domain.UnifiedFlight {
+	minConnection := a.SelfTransfer.MinConnection
+	if criteria.MinSelfTransferMinutes != nil {
+		minConnection = time.Duration(*criteria.MinSelfTransferMinutes) * time.Minute
+	}
+	minConnection = max(minConnection, 0)
+
+	secondDates := []string{criteria.DepartureDate}
+	if day, err := time.Parse(dateLayout, criteria.DepartureDate); err == nil {
+		secondDates = append(secondDates, day.AddDate(0, 0, 1).Format(dateLayout))
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var res []domain.UnifiedFlight
+
+	for _, hub := range a.SelfTransfer.Hubs {
+		if hub == criteria.Origin || hub == criteria.Destination {
+			continue
+		}
+
+		wg.Add(1)
+		go func(hub string) {
+			defer wg.Done()
+
+			var first domain.SearchResponse
+			seconds := make([]domain.SearchResponse, len(secondDates))
+			var legs sync.WaitGroup
+			legs.Add(1 + len(secondDates))
+			go func() {
+				defer legs.Done()
+				first = a.searchOneWay(ctx, selfTransferLegCriteria(criteria, criteria.Origin, hub, criteria.DepartureDate))
+			}()
+			for i, date := range secondDates {
+				go func() {
+					defer legs.Done()
+					seconds[i] = a.searchOneWay(ctx, selfTransferLegCriteria(criteria, hub, criteria.Destination, date))
+				}()
+			}
+			legs.Wait()
+
+			var combined []domain.UnifiedFlight
+			for _, second := range seconds {
+				combined = append(combined, combineSelfTransfer(first.Flights, second.Flights, minConnection, a.SelfTransfer.MaxConnection)...)
+			}
+
+			mu.Lock()
+			res = append(res, combined...)
+			mu.Unlock()
+		}(hub)
+	}
+	wg.Wait()
+
+	return res
+}
+
+func selfTransferLegCriteria(c domain.SearchCriteria, origin, destination, date string) domain.SearchCriteria {
+	return domain.SearchCriteria{
+		Origin:        origin,
+		Destination:   destination,
+		DepartureDate: date,
+		Passengers:    c.Passengers,
+		CabinClass:    c.CabinClass,
+		Filters:       domain.FilterOptions{Airlines: c.Filters.Airlines},
+		SortBy:        "best_value",
+	}
+}
+
+func combineSelfTransfer(first, second []domain.UnifiedFlight, minConnection, maxConnection time.Duration) []domain.UnifiedFlight {
+	if len(first) > maxCandidatesPerLeg {
+		first = first[:maxCandidatesPerLeg]
+	}
+	if len(second) > maxCandidatesPerLeg {
+		second = second[:maxCandidatesPerLeg]
+	}
+
+	var res []domain.UnifiedFlight
+	for _, f1 := range first {
+		for _, f2 := range second {
+			// Leg kedua harus berangkat setelah leg pertama tiba, berapa pun minConnection.
+			connection := time.Duration(f2.Departure.Timestamp-f1.Arrival.Timestamp) * time.Second
+			if connection <= 0 || connection < minConnection || connection > maxConnection {
+				continue
+			}
+			res = append(res, newSelfTransferFlight(f1, f2, connection, minConnection))
+		}
+	}
+	return res
+}
+
+func newSelfTransferFlight(f1, f2 domain.UnifiedFlight, connection, minConnection time.Duration) domain.UnifiedFlight {
+	totalPrice := f1.Price.Amount + f2.Price.Amount
+	dur := providers.CalculateDuration(f1.Departure.TimeOfDay, f2.Arrival.TimeOfDay)
+
+	var layovers []domain.Layover
+	layovers = append(layovers, f1.Layovers...)
+	layovers = append(layovers, domain.Layover{
+		Airport:         f1.Arrival.Airport,
+		City:            f1.Arrival.City,
+		DurationMinutes: int(connection.Minutes()),
+	})
+	layovers = append(layovers, f2.Layovers...)
+
+	seats := f1.AvailableSeats
+	if f2.AvailableSeats < seats {
+		seats = f2.AvailableSeats
+	}
+
+	airline := f1.Airline
+	if f1.Airline.Code != f2.Airline.Code {
+		airline = domain.AirlineInfo{
+			Name: f1.Airline.Name + " + " + f2.Airline.Name,
+			Code: f1.Airline.Code + "+" + f2.Airline.Code,
+		}
+	}
+
+	provider := f1.Provider
+	if f1.Provider != f2.Provider {
+		provider = f1.Provider + " + " + f2.Provider
+	}
+
+	return domain.UnifiedFlight{
+		ID:           f1.ID + "+" + f2.ID,
+		Provider:     provider,
+		Airline:      airline,
+		FlightNumber: strings.Join([]string{f1.FlightNumber, f2.FlightNumber}, "/"),
+		Departure:    f1.Departure,
+		Arrival:      f2.Arrival,
+		Duration: domain.DurationInfo{
+			TotalMinutes: dur,
+			Formatted:    formatDuration(dur),
+		},
+		Stops:    f1.Stops + f2.Stops + 1,
+		Segments: append(legSegments(f1), legSegments(f2)...),
+		Layovers: layovers,
+		Price: domain.PriceInfo{
+			Amount:          totalPrice,
+			FormattedAmount: providers.FormatIDR(totalPrice),
+			Currency:        f1.Price.Currency,
+		},
+		AvailableSeats:   seats,
+		CabinClass:       f1.CabinClass,
+		SelfTransfer:     true,
+		SelfTransferRisk: selfTransferRisk(connection, minConnection),
+		IsValid:          true,
+	}
+}
+
+func legSegments(f domain.UnifiedFlight) []domain.Segment {
+	if len(f.Segments) > 0 {
+		return append([]domain.Segment(nil), f.Segments...)
+	}
+	return []domain.Segment{{
+		FlightNumber:     f.FlightNumber,
+		OperatingCarrier: f.Airline.Code,
+		Departure:        f.Departure,
+		Arrival:          f.Arrival,
+		Duration:         f.Duration,
+		Aircraft:         f.Aircraft,
+	}}
+}
+
+// selfTransferRisk menilai risiko ketinggalan penerbangan lanjutan berdasarkan sisa waktu
+// di atas minimum connection time. Tiket terpisah tidak dilindungi jika leg pertama terlambat.
+func selfTransferRisk(connection, minConnection time.Duration) string {
+	slack := connection - minConnection
+	switch {
+	case slack < time.Hour:
+		return SelfTransferRiskHigh
+	case slack < 3*time.Hour:
+		return SelfTransferRiskMedium
+	default:
+		return SelfTransferRiskLow
+	}
+}
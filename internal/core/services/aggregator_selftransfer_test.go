@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCombineSelfTransfer(t *testing.T) {
+	day := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	// Leg pertama tiba di SUB pukul 10:00.
+	first := []domain.UnifiedFlight{stubFlight("A1", "CGK", "SUB", day.Add(8*time.Hour), 120, 500000)}
+
+	tests := []struct {
+		name      string
+		departure time.Duration
+		min       time.Duration
+		wantRisk  string
+	}{
+		{"departs before arrival with negative minimum", 9 * time.Hour, -600 * time.Minute, ""},
+		{"departs at arrival with zero minimum", 10 * time.Hour, 0, ""},
+		{"below minimum connection", 12*time.Hour + 59*time.Minute, 3 * time.Hour, ""},
+		{"exactly minimum connection", 13 * time.Hour, 3 * time.Hour, SelfTransferRiskHigh},
+		{"under one hour of slack", 13*time.Hour + 59*time.Minute, 3 * time.Hour, SelfTransferRiskHigh},
+		{"one hour of slack", 14 * time.Hour, 3 * time.Hour, SelfTransferRiskMedium},
+		{"under three hours of slack", 15*time.Hour + 59*time.Minute, 3 * time.Hour, SelfTransferRiskMedium},
+		{"three hours of slack", 16 * time.Hour, 3 * time.Hour, SelfTransferRiskLow},
+		{"exactly maximum connection", 22 * time.Hour, 3 * time.Hour, SelfTransferRiskLow},
+		{"above maximum connection", 22*time.Hour + time.Minute, 3 * time.Hour, ""},
+	}
+
+	for _, tt := range tests {
+		second := []domain.UnifiedFlight{stubFlight("B1", "SUB", "DPS", day.Add(tt.departure), 60, 400000)}
+		res := combineSelfTransfer(first, second, tt.min, 12*time.Hour)
+
+		if tt.wantRisk == "" {
+			if len(res) != 0 {
+				t.Errorf("%s: got %d itineraries, want none", tt.name, len(res))
+			}
+			continue
+		}
+		if len(res) != 1 {
+			t.Errorf("%s: got %d itineraries, want 1", tt.name, len(res))
+			continue
+		}
+
+		f := res[0]
+		connection := int((tt.departure - 10*time.Hour).Minutes())
+		if f.SelfTransferRisk != tt.wantRisk {
+			t.Errorf("%s: risk = %s, want %s", tt.name, f.SelfTransferRisk, tt.wantRisk)
+		}
+		if len(f.Layovers) != 1 || f.Layovers[0].Airport != "SUB" || f.Layovers[0].DurationMinutes != connection {
+			t.Errorf("%s: layovers = %+v, want %d minutes at SUB", tt.name, f.Layovers, connection)
+		}
+		if f.Duration.TotalMinutes != 120+connection+60 || f.Price.Amount != 900000 || f.Stops != 1 {
+			t.Errorf("%s: duration %d, price %v, stops %d", tt.name, f.Duration.TotalMinutes, f.Price.Amount, f.Stops)
+		}
+	}
+}
+
+func TestSelfTransferConnectsPastMidnight(t *testing.T) {
+	p := &stubProvider{name: "Stub", search: func(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+		day, _ := time.Parse(dateLayout, c.DepartureDate)
+		switch c.Origin + "-" + c.Destination {
+		case "CGK-SUB":
+			return []domain.UnifiedFlight{stubFlight("A1-"+c.DepartureDate, "CGK", "SUB", day.Add(18*time.Hour), 120, 500000)}, nil
+		case "SUB-DPS":
+			return []domain.UnifiedFlight{stubFlight("B1-"+c.DepartureDate, "SUB", "DPS", day.Add(2*time.Hour), 60, 400000)}, nil
+		}
+		return nil, nil
+	}}
+	a := newTestAggregator(p)
+	a.SelfTransfer.Hubs = []string{"SUB"}
+
+	resp := a.SearchFlights(context.Background(), domain.SearchCriteria{
+		Origin:              "CGK",
+		Destination:         "DPS",
+		DepartureDate:       "2025-12-15",
+		IncludeSelfTransfer: true,
+	})
+
+	if len(resp.Flights) != 1 || resp.Flights[0].ID != "A1-2025-12-15+B1-2025-12-16" {
+		t.Fatalf("got %d flights, want A1-2025-12-15+B1-2025-12-16", len(resp.Flights))
+	}
+	if got := resp.Flights[0].Layovers[0].DurationMinutes; got != 6*60 {
+		t.Errorf("connection = %d minutes, want 360", got)
+	}
+}
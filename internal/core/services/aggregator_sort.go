@@ -2,38 +2,164 @@ package services
 
 import (
 	"bookcabin-test/internal/core/domain"
-	"sort"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
 )
 
-func calculateBestValue(flights []domain.UnifiedFlight) []domain.UnifiedFlight {
-	for i := range flights {
-		f := &flights[i]
-		score := (f.Price.Amount / 1000.0) + (float64(f.Duration.TotalMinutes) * 5.0) + (float64(f.Stops) * 5000.0)
-		f.Score = score
-	}
-	return flights
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	SortKeyBestValue = "best_value"
+	SortKeyPrice     = "price"
+	SortKeyDuration  = "duration"
+	SortKeyDeparture = "departure"
+	SortKeyArrival   = "arrival"
+	SortKeyStops     = "stops"
+	SortKeyAirline   = "airline"
+)
+
+var sortKeys = map[string]bool{
+	SortKeyBestValue: true,
+	SortKeyPrice:     true,
+	SortKeyDuration:  true,
+	SortKeyDeparture: true,
+	SortKeyArrival:   true,
+	SortKeyStops:     true,
+	SortKeyAirline:   true,
+}
+
+// legacySortAliases mempertahankan nilai sortBy lama.
+var legacySortAliases = map[string]domain.SortKey{
+	"best_value":    {Key: SortKeyBestValue, Direction: SortAsc},
+	"price_asc":     {Key: SortKeyPrice, Direction: SortAsc},
+	"price_desc":    {Key: SortKeyPrice, Direction: SortDesc},
+	"duration_asc":  {Key: SortKeyDuration, Direction: SortAsc},
+	"duration_desc": {Key: SortKeyDuration, Direction: SortDesc},
+	"dep_time_asc":  {Key: SortKeyDeparture, Direction: SortAsc},
+	"arr_time_asc":  {Key: SortKeyArrival, Direction: SortAsc},
 }
 
-func sortFlights(flights []domain.UnifiedFlight, sortType string) []domain.UnifiedFlight {
-	sort.Slice(flights, func(i, j int) bool {
-		switch sortType {
-		case "price_asc":
-			return flights[i].Price.Amount < flights[j].Price.Amount
-		case "price_desc":
-			return flights[i].Price.Amount > flights[j].Price.Amount
-		case "duration_asc":
-			return flights[i].Duration.TotalMinutes < flights[j].Duration.TotalMinutes
-		case "duration_desc":
-			return flights[i].Duration.TotalMinutes > flights[j].Duration.TotalMinutes
-		case "dep_time_asc":
-			return flights[i].Departure.Timestamp < flights[j].Departure.Timestamp
-		case "arr_time_asc":
-			return flights[i].Arrival.Timestamp < flights[j].Arrival.Timestamp
-		case "best_value":
-			fallthrough
+// ParseSort menghasilkan daftar kunci pengurutan. Jika keys kosong, sortBy dibaca sebagai daftar
+// dipisah koma berisi nilai lama (mis. "price_asc") atau "key[:asc|desc]", mis. "price,departure:desc".
+// Tanpa keduanya, hasilnya best_value.
+func ParseSort(sortBy string, keys []domain.SortKey) ([]domain.SortKey, error) {
+	if len(keys) == 0 {
+		for _, token := range strings.Split(sortBy, ",") {
+			token = strings.TrimSpace(token)
+			if token == "" {
+				continue
+			}
+			if alias, ok := legacySortAliases[token]; ok {
+				keys = append(keys, alias)
+				continue
+			}
+			key, direction, _ := strings.Cut(token, ":")
+			keys = append(keys, domain.SortKey{Key: key, Direction: direction})
+		}
+	}
+
+	if len(keys) == 0 {
+		return []domain.SortKey{legacySortAliases["best_value"]}, nil
+	}
+
+	spec := make([]domain.SortKey, len(keys))
+	seen := map[string]bool{}
+	for i, k := range keys {
+		if !sortKeys[k.Key] {
+			return nil, fmt.Errorf("unknown sort key %q", k.Key)
+		}
+		if seen[k.Key] {
+			return nil, fmt.Errorf("duplicate sort key %q", k.Key)
+		}
+		seen[k.Key] = true
+		switch k.Direction {
+		case "":
+			k.Direction = SortAsc
+		case SortAsc, SortDesc:
 		default:
-			return flights[i].Score < flights[j].Score
+			return nil, fmt.Errorf("sort direction for %q must be asc or desc", k.Key)
 		}
+		spec[i] = k
+	}
+	return spec, nil
+}
+
+// sortSpecFor mengembalikan kunci pengurutan untuk kriteria; kriteria tidak valid (sudah ditolak
+// di handler) jatuh ke best_value.
+func sortSpecFor(sortBy string, keys []domain.SortKey) []domain.SortKey {
+	spec, err := ParseSort(sortBy, keys)
+	if err != nil {
+		return []domain.SortKey{legacySortAliases["best_value"]}
+	}
+	return spec
+}
+
+// sortMetrics adalah nilai yang bisa diurutkan dari penerbangan maupun itinerary.
+type sortMetrics struct {
+	score     float64
+	price     float64
+	duration  int
+	departure int64
+	arrival   int64
+	stops     int
+	airline   string
+	id        string
+}
+
+func (m sortMetrics) compare(other sortMetrics, key string) int {
+	switch key {
+	case SortKeyPrice:
+		return cmp.Compare(m.price, other.price)
+	case SortKeyDuration:
+		return cmp.Compare(m.duration, other.duration)
+	case SortKeyDeparture:
+		return cmp.Compare(m.departure, other.departure)
+	case SortKeyArrival:
+		return cmp.Compare(m.arrival, other.arrival)
+	case SortKeyStops:
+		return cmp.Compare(m.stops, other.stops)
+	case SortKeyAirline:
+		return cmp.Compare(m.airline, other.airline)
+	default:
+		return cmp.Compare(m.score, other.score)
+	}
+}
+
+// sortStable mengurutkan items secara stabil berdasarkan spec, dengan ID sebagai pemutus seri
+// terakhir sehingga urutan selalu sama di setiap pemanggilan.
+func sortStable[T any](items []T, spec []domain.SortKey, metrics func(*T) sortMetrics) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		ma, mb := metrics(&a), metrics(&b)
+		for _, k := range spec {
+			c := ma.compare(mb, k.Key)
+			if k.Direction == SortDesc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(ma.id, mb.id)
 	})
+}
+
+func flightMetrics(f *domain.UnifiedFlight) sortMetrics {
+	return sortMetrics{
+		score:     f.Score,
+		price:     f.Price.Amount,
+		duration:  f.Duration.TotalMinutes,
+		departure: f.Departure.Timestamp,
+		arrival:   f.Arrival.Timestamp,
+		stops:     f.Stops,
+		airline:   f.Airline.Name,
+		id:        f.ID + "|" + f.Provider,
+	}
+}
+
+func sortFlights(flights []domain.UnifiedFlight, spec []domain.SortKey) []domain.UnifiedFlight {
+	sortStable(flights, spec, flightMetrics)
 	return flights
 }
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSortFlights(t *testing.T) {
+	day := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	flight := func(id, provider, airline string, dep time.Duration, minutes int, price float64) domain.UnifiedFlight {
+		f := stubFlight(id, "CGK", "DPS", day.Add(dep), minutes, price)
+		f.Provider, f.Airline.Name = provider, airline
+		return f
+	}
+	flights := []domain.UnifiedFlight{
+		flight("F3", "Garuda", "Garuda Indonesia", 9*time.Hour, 110, 800000),
+		flight("F1", "Lion", "Lion Air", 7*time.Hour, 120, 500000),
+		flight("F2", "Lion", "Lion Air", 7*time.Hour, 100, 500000),
+		flight("F1", "AirAsia", "AirAsia", 6*time.Hour, 120, 500000),
+		flight("F4", "Batik", "Batik Air", 9*time.Hour, 110, 800000),
+	}
+
+	tests := []struct {
+		name string
+		spec []domain.SortKey
+		want []string
+	}{
+		{"price then departure", []domain.SortKey{{Key: SortKeyPrice}, {Key: SortKeyDeparture}},
+			[]string{"F1|AirAsia", "F1|Lion", "F2|Lion", "F3|Garuda", "F4|Batik"}},
+		{"price desc then duration", []domain.SortKey{{Key: SortKeyPrice, Direction: SortDesc}, {Key: SortKeyDuration}},
+			[]string{"F3|Garuda", "F4|Batik", "F2|Lion", "F1|AirAsia", "F1|Lion"}},
+		{"airline desc then departure desc", []domain.SortKey{{Key: SortKeyAirline, Direction: SortDesc}, {Key: SortKeyDeparture, Direction: SortDesc}},
+			[]string{"F1|Lion", "F2|Lion", "F3|Garuda", "F4|Batik", "F1|AirAsia"}},
+		// Semua seri: urutan jatuh ke ID|Provider.
+		{"ties fall through to id and provider", []domain.SortKey{{Key: SortKeyStops}},
+			[]string{"F1|AirAsia", "F1|Lion", "F2|Lion", "F3|Garuda", "F4|Batik"}},
+	}
+
+	for _, tt := range tests {
+		// Urutan input dibalik agar hasil tidak bergantung pada urutan asal.
+		for _, input := range [][]domain.UnifiedFlight{slices.Clone(flights), reversed(flights)} {
+			var got []string
+			for _, f := range sortFlights(input, tt.spec) {
+				got = append(got, f.ID+"|"+f.Provider)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func reversed(flights []domain.UnifiedFlight) []domain.UnifiedFlight {
+	out := slices.Clone(flights)
+	slices.Reverse(out)
+	return out
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		sortBy  string
+		keys    []domain.SortKey
+		want    []domain.SortKey
+		wantErr string
+	}{
+		{name: "empty defaults to best value", want: []domain.SortKey{{Key: SortKeyBestValue, Direction: SortAsc}}},
+		{name: "legacy alias", sortBy: "price_desc", want: []domain.SortKey{{Key: SortKeyPrice, Direction: SortDesc}}},
+		{name: "comma list", sortBy: "price, departure:desc",
+			want: []domain.SortKey{{Key: SortKeyPrice, Direction: SortAsc}, {Key: SortKeyDeparture, Direction: SortDesc}}},
+		{name: "keys take precedence over sortBy", sortBy: "price_desc", keys: []domain.SortKey{{Key: SortKeyStops}, {Key: SortKeyAirline, Direction: SortDesc}},
+			want: []domain.SortKey{{Key: SortKeyStops, Direction: SortAsc}, {Key: SortKeyAirline, Direction: SortDesc}}},
+		{name: "unknown key", sortBy: "cheapest", wantErr: `unknown sort key "cheapest"`},
+		{name: "unknown key in list", keys: []domain.SortKey{{Key: SortKeyPrice}, {Key: "seats"}}, wantErr: `unknown sort key "seats"`},
+		{name: "duplicate key", sortBy: "price,price:desc", wantErr: `duplicate sort key "price"`},
+		{name: "duplicate key through alias", sortBy: "price_asc,price", wantErr: `duplicate sort key "price"`},
+		{name: "bad direction", sortBy: "price:up", wantErr: `sort direction for "price" must be asc or desc`},
+		{name: "bad direction in list", keys: []domain.SortKey{{Key: SortKeyDuration, Direction: "DESC"}}, wantErr: `sort direction for "duration"`},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSort(tt.sortBy, tt.keys)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// Cache adalah backend penyimpanan hasil fetch yang dipakai Aggregator. Implementasinya harus
+// aman dipakai dari banyak goroutine; error backend diperlakukan sebagai cache miss.
+type Cache interface {
+	Load(ctx context.Context, key string) (CachedResponse, bool)
+	Store(ctx context.Context, key string, value CachedResponse, ttl time.Duration)
+	Delete(ctx context.Context, key string)
+}
+
+// CacheStatsReporter diimplementasikan oleh backend yang bisa melaporkan statistik ke operator.
+type CacheStatsReporter interface {
+	Stats() CacheStats
+}
+
+type CacheStats struct {
+	Backend     string `json:"backend"`
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"approx_bytes"`
+	MaxEntries  int    `json:"max_entries,omitempty"`
+	MaxBytes    int64  `json:"max_bytes,omitempty"`
+	Hits        int64  `json:"hits"`
+	Misses      int64  `json:"misses"`
+	Evictions   int64  `json:"evictions"`
+	Expirations int64  `json:"expirations"`
+	Errors      int64  `json:"errors"`
+}
+
+// CacheStats mengembalikan statistik backend cache, atau nil jika backend tidak melaporkannya.
+func (a *Aggregator) CacheStats() *CacheStats {
+	reporter, ok := a.FlightCache.(CacheStatsReporter)
+	if !ok {
+		return nil
+	}
+	stats := reporter.Stats()
+	return &stats
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCacheMaxEntries      = 10000
+	DefaultCacheMaxBytes        = 64 << 20
+	DefaultCacheJanitorInterval = 30 * time.Second
+)
+
+type LRUConfig struct {
+	MaxEntries      int
+	MaxBytes        int64
+	JanitorInterval time.Duration
+}
+
+func DefaultLRUConfig() LRUConfig {
+	return LRUConfig{
+		MaxEntries:      DefaultCacheMaxEntries,
+		MaxBytes:        DefaultCacheMaxBytes,
+		JanitorInterval: DefaultCacheJanitorInterval,
+	}
+}
+
+type lruEntry struct {
+	key       string
+	value     CachedResponse
+	size      int64
+	expiresAt time.Time
+}
+
+// LRUCache adalah cache in-memory yang dibatasi jumlah entry dan perkiraan ukuran byte.
+// Entry yang paling lama tidak dipakai dibuang lebih dulu, dan janitor berkala membersihkan
+// entry yang sudah melewati TTL-nya.
+type LRUCache struct {
+	cfg LRUConfig
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+	stats CacheStats
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func NewLRUCache(cfg LRUConfig) *LRUCache {
+	c := &LRUCache{
+		cfg:   cfg,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+		stop:  make(chan struct{}),
+	}
+	if cfg.JanitorInterval > 0 {
+		go c.janitor(cfg.JanitorInterval)
+	}
+	return c
+}
+
+func (c *LRUCache) Load(ctx context.Context, key string) (CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return CachedResponse{}, false
+	}
+
+	entry := el.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return CachedResponse{}, false
+	}
+
+	c.ll.MoveToFront(el)
+	c.stats.Hits++
+	return entry.value, true
+}
+
+// Store menyimpan value dengan TTL; ttl <= 0 berarti entry hanya dibuang oleh eviction LRU.
+func (c *LRUCache) Store(ctx context.Context, key string, value CachedResponse, ttl time.Duration) {
+	entry := &lruEntry{key: key, value: value, size: approxSize(key, value)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+
+	c.items[key] = c.ll.PushFront(entry)
+	c.bytes += entry.size
+
+	for c.overLimit() && c.ll.Len() > 1 {
+		c.removeElement(c.ll.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *LRUCache) Delete(ctx context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *LRUCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Backend = "memory"
+	stats.Entries = c.ll.Len()
+	stats.Bytes = c.bytes
+	stats.MaxEntries = c.cfg.MaxEntries
+	stats.MaxBytes = c.cfg.MaxBytes
+	return stats
+}
+
+func (c *LRUCache) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+func (c *LRUCache) overLimit() bool {
+	return (c.cfg.MaxEntries > 0 && c.ll.Len() > c.cfg.MaxEntries) ||
+		(c.cfg.MaxBytes > 0 && c.bytes > c.cfg.MaxBytes)
+}
+
+func (c *LRUCache) removeElement(el *list.Element) {
+	entry := el.Value.(*lruEntry)
+	c.ll.Remove(el)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+func (c *LRUCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.removeExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *LRUCache) removeExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for el := c.ll.Back(); el != nil; {
+		prev := el.Prev()
+		entry := el.Value.(*lruEntry)
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			c.removeElement(el)
+			c.stats.Expirations++
+		}
+		el = prev
+	}
+}
+
+// approxSize memperkirakan ukuran entry dari panjang serialisasi JSON-nya.
+func approxSize(key string, value CachedResponse) int64 {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return int64(len(key))
+	}
+	return int64(len(key) + len(data))
+}
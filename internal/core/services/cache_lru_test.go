@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func entry(provider string) CachedResponse {
+	return CachedResponse{Provider: provider, Timestamp: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)}
+}
+
+func cachedKeys(c *LRUCache, keys ...string) []string {
+	var res []string
+	for _, k := range keys {
+		c.mu.Lock()
+		_, ok := c.items[k]
+		c.mu.Unlock()
+		if ok {
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(LRUConfig{MaxEntries: 3})
+	defer c.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		c.Store(ctx, k, entry(k), 0)
+	}
+	c.Load(ctx, "a")
+	c.Store(ctx, "d", entry("d"), 0)
+
+	if got := cachedKeys(c, "a", "b", "c", "d"); len(got) != 3 || got[0] != "a" || got[1] != "c" || got[2] != "d" {
+		t.Fatalf("cached keys = %v, want [a c d]", got)
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Entries != 3 {
+		t.Errorf("stats = %+v, want 1 eviction and 3 entries", stats)
+	}
+}
+
+func TestLRUByteLimit(t *testing.T) {
+	ctx := context.Background()
+	size := approxSize("a", entry("a"))
+	c := NewLRUCache(LRUConfig{MaxBytes: size*2 + size/2})
+	defer c.Close()
+
+	c.Store(ctx, "a", entry("a"), 0)
+	c.Store(ctx, "b", entry("b"), 0)
+	if stats := c.Stats(); stats.Bytes != 2*size || stats.Evictions != 0 {
+		t.Fatalf("stats = %+v, want %d bytes and no evictions", stats, 2*size)
+	}
+
+	c.Store(ctx, "c", entry("c"), 0)
+	stats := c.Stats()
+	if stats.Bytes > stats.MaxBytes || stats.Evictions != 1 {
+		t.Errorf("stats = %+v, want at most %d bytes after 1 eviction", stats, stats.MaxBytes)
+	}
+	if got := cachedKeys(c, "a", "b", "c"); len(got) != 2 || got[0] != "b" {
+		t.Errorf("cached keys = %v, want [b c]", got)
+	}
+
+	// Menimpa key yang sama tidak menghitung ukurannya dua kali.
+	c.Store(ctx, "c", entry("c"), 0)
+	if got := c.Stats().Bytes; got != 2*size {
+		t.Errorf("bytes after overwrite = %d, want %d", got, 2*size)
+	}
+}
+
+func TestLRUKeepsSingleOversizedEntry(t *testing.T) {
+	c := NewLRUCache(LRUConfig{MaxBytes: 1})
+	defer c.Close()
+
+	c.Store(context.Background(), "a", entry("a"), 0)
+	if _, ok := c.Load(context.Background(), "a"); !ok {
+		t.Error("the most recent entry should stay even when it exceeds MaxBytes")
+	}
+}
+
+func TestLRUExpiresOnLoad(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(LRUConfig{})
+	defer c.Close()
+
+	c.Store(ctx, "short", entry("short"), 10*time.Millisecond)
+	c.Store(ctx, "forever", entry("forever"), 0)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Load(ctx, "short"); ok {
+		t.Error("expired entry was returned")
+	}
+	if _, ok := c.Load(ctx, "forever"); !ok {
+		t.Error("entry without TTL was expired")
+	}
+	if stats := c.Stats(); stats.Expirations != 1 || stats.Entries != 1 {
+		t.Errorf("stats = %+v, want 1 expiration and 1 entry left", stats)
+	}
+}
+
+func TestLRUJanitorRemovesExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(LRUConfig{JanitorInterval: 5 * time.Millisecond})
+	defer c.Close()
+
+	c.Store(ctx, "a", entry("a"), 10*time.Millisecond)
+	c.Store(ctx, "b", entry("b"), 10*time.Millisecond)
+	c.Store(ctx, "c", entry("c"), time.Hour)
+
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().Entries != 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 1 || stats.Expirations != 2 || stats.Bytes != approxSize("c", entry("c")) {
+		t.Errorf("stats = %+v, want only c left after 2 expirations", stats)
+	}
+	if stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("janitor touched hit/miss counters: %+v", stats)
+	}
+}
+
+func TestLRUStatsCounters(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(LRUConfig{MaxEntries: 10, MaxBytes: 1 << 20})
+	defer c.Close()
+
+	c.Store(ctx, "a", entry("a"), 0)
+	c.Load(ctx, "a")
+	c.Load(ctx, "a")
+	c.Load(ctx, "missing")
+	c.Delete(ctx, "a")
+	c.Load(ctx, "a")
+
+	stats := c.Stats()
+	want := CacheStats{Backend: "memory", MaxEntries: 10, MaxBytes: 1 << 20, Hits: 2, Misses: 2}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
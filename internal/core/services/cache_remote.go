@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultRemoteCachePrefix = "flights:"
+
+// BlobStore adalah key-value store eksternal (mis. Redis) yang menyimpan byte dengan TTL
+// yang ditegakkan di sisi server.
+type BlobStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// RemoteCache menyimpan CachedResponse dalam bentuk JSON di BlobStore sehingga bisa dipakai
+// bersama oleh beberapa replika.
+type RemoteCache struct {
+	Blobs  BlobStore
+	Prefix string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+func NewRemoteCache(store BlobStore) *RemoteCache {
+	return &RemoteCache{Blobs: store, Prefix: DefaultRemoteCachePrefix}
+}
+
+func (c *RemoteCache) Load(ctx context.Context, key string) (CachedResponse, bool) {
+	data, ok, err := c.Blobs.Get(ctx, c.Prefix+key)
+	if err != nil {
+		c.errors.Add(1)
+		log.Printf("cache: get %s failed: %v", key, err)
+		c.misses.Add(1)
+		return CachedResponse{}, false
+	}
+	if !ok {
+		c.misses.Add(1)
+		return CachedResponse{}, false
+	}
+
+	var value CachedResponse
+	if err := json.Unmarshal(data, &value); err != nil {
+		c.errors.Add(1)
+		log.Printf("cache: decode %s failed: %v", key, err)
+		c.misses.Add(1)
+		return CachedResponse{}, false
+	}
+
+	c.hits.Add(1)
+	return value, true
+}
+
+func (c *RemoteCache) Store(ctx context.Context, key string, value CachedResponse, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.errors.Add(1)
+		log.Printf("cache: encode %s failed: %v", key, err)
+		return
+	}
+
+	if err := c.Blobs.Set(ctx, c.Prefix+key, data, ttl); err != nil {
+		c.errors.Add(1)
+		log.Printf("cache: set %s failed: %v", key, err)
+	}
+}
+
+func (c *RemoteCache) Delete(ctx context.Context, key string) {
+	if err := c.Blobs.Del(ctx, c.Prefix+key); err != nil {
+		c.errors.Add(1)
+		log.Printf("cache: del %s failed: %v", key, err)
+	}
+}
+
+func (c *RemoteCache) Stats() CacheStats {
+	return CacheStats{
+		Backend: "remote",
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Errors:  c.errors.Load(),
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/redis"
+	"bookcabin-test/internal/platform/redis/redistest"
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestRemoteCache(t *testing.T) (*RemoteCache, *redistest.Server) {
+	t.Helper()
+	srv, err := redistest.StartServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(redis.Config{Addr: srv.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+		srv.Close()
+	})
+	return NewRemoteCache(client), srv
+}
+
+func TestRemoteCacheRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestRemoteCache(t)
+
+	wita := time.FixedZone("WITA", 8*3600)
+	flight := stubFlight("GA400", "CGK", "DPS", time.Date(2025, 12, 15, 6, 0, 0, 0, time.FixedZone("WIB", 7*3600)), 110, 1250000)
+	flight.Arrival.TimeOfDay = flight.Arrival.TimeOfDay.In(wita)
+	flight.Layovers = []domain.Layover{{Airport: "SUB", DurationMinutes: 45}}
+	flight.Offers = []domain.Offer{{Provider: "Garuda Indonesia", FlightID: "GA400", Price: flight.Price, AvailableSeats: 9}}
+
+	value := CachedResponse{
+		Provider:  "Garuda Indonesia",
+		Flights:   []domain.UnifiedFlight{flight},
+		Outcome:   domain.ProviderOutcome{Name: "Garuda Indonesia", Status: domain.ProviderStatusOK, ResultCount: 1, Warnings: []string{"w"}},
+		Timestamp: time.Now().Truncate(time.Millisecond),
+		TTL:       time.Minute,
+		Snapshot: &domain.SearchResponse{
+			Flights:  []domain.UnifiedFlight{flight},
+			Metadata: domain.ResponseMetadata{TotalResults: 1, ProvidersQueried: 4},
+		},
+	}
+	cache.Store(ctx, "key", value, time.Minute)
+
+	got, ok := cache.Load(ctx, "key")
+	if !ok {
+		t.Fatal("stored value was not found")
+	}
+	if got.Provider != value.Provider || got.TTL != value.TTL || !got.Timestamp.Equal(value.Timestamp) {
+		t.Errorf("header = %s/%v/%v, want %s/%v/%v", got.Provider, got.TTL, got.Timestamp, value.Provider, value.TTL, value.Timestamp)
+	}
+	if got.Outcome.Status != domain.ProviderStatusOK || len(got.Outcome.Warnings) != 1 {
+		t.Errorf("outcome = %+v", got.Outcome)
+	}
+
+	for name, flights := range map[string][]domain.UnifiedFlight{"flights": got.Flights, "snapshot": got.Snapshot.Flights} {
+		if len(flights) != 1 {
+			t.Fatalf("%s: got %d flights", name, len(flights))
+		}
+		f := flights[0]
+		if !f.Departure.TimeOfDay.Equal(flight.Departure.TimeOfDay) || !f.Arrival.TimeOfDay.Equal(flight.Arrival.TimeOfDay) {
+			t.Errorf("%s: times = %v → %v, want %v → %v", name, f.Departure.TimeOfDay, f.Arrival.TimeOfDay, flight.Departure.TimeOfDay, flight.Arrival.TimeOfDay)
+		}
+		// Jam lokal (dipakai filter waktu) harus tetap sama setelah serialisasi.
+		if f.Arrival.TimeOfDay.Hour() != flight.Arrival.TimeOfDay.Hour() {
+			t.Errorf("%s: arrival hour = %d, want %d", name, f.Arrival.TimeOfDay.Hour(), flight.Arrival.TimeOfDay.Hour())
+		}
+		if f.Price != flight.Price || len(f.Layovers) != 1 || len(f.Offers) != 1 {
+			t.Errorf("%s: flight = %+v", name, f)
+		}
+	}
+	if got.Snapshot.Metadata.TotalResults != 1 {
+		t.Errorf("snapshot metadata = %+v", got.Snapshot.Metadata)
+	}
+
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 0 || stats.Errors != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestRemoteCacheTTLAndDelete(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestRemoteCache(t)
+
+	cache.Store(ctx, "short", CachedResponse{Provider: "A"}, 20*time.Millisecond)
+	cache.Store(ctx, "gone", CachedResponse{Provider: "B"}, time.Minute)
+	cache.Delete(ctx, "gone")
+
+	if _, ok := cache.Load(ctx, "gone"); ok {
+		t.Error("deleted entry was returned")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := cache.Load(ctx, "short"); ok {
+		t.Error("entry outlived its server-side TTL")
+	}
+}
+
+func TestRemoteCacheTreatsErrorsAsMisses(t *testing.T) {
+	ctx := context.Background()
+	cache, srv := newTestRemoteCache(t)
+
+	cache.Store(ctx, "k", CachedResponse{Provider: "A"}, time.Minute)
+	srv.FailNext("LOADING Redis is loading the dataset in memory")
+	if _, ok := cache.Load(ctx, "k"); ok {
+		t.Error("load returned a value despite a server error")
+	}
+	if _, ok := cache.Load(ctx, "k"); !ok {
+		t.Error("load failed after the server recovered")
+	}
+
+	if stats := cache.Stats(); stats.Errors != 1 || stats.Misses != 1 || stats.Hits != 1 {
+		t.Errorf("stats = %+v, want 1 error, 1 miss, 1 hit", stats)
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultPrewarmDays     = 30
+	DefaultPrewarmInterval = 10 * time.Minute
+	DefaultPrewarmRate     = 5.0
+)
+
+type PrewarmRoute struct {
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	CabinClass  string `json:"cabinClass"`
+}
+
+// criteria membentuk criteria pencarian rute ini; kode metro diperluas oleh concreteRoutes.
+func (r PrewarmRoute) criteria(date string) domain.SearchCriteria {
+	return domain.SearchCriteria{
+		Origin:        r.Origin,
+		Destination:   r.Destination,
+		DepartureDate: date,
+		CabinClass:    r.CabinClass,
+		Passengers:    1,
+	}
+}
+
+type PrewarmConfig struct {
+	Routes []PrewarmRoute
+	// StartDate (format 2006-01-02) kosong berarti mulai dari hari ini (WIB).
+	StartDate string
+	Days      int
+	Interval  time.Duration
+	// RateLimits membatasi jumlah request pre-warm per detik per nama provider;
+	// provider yang tidak terdaftar memakai DefaultRate.
+	RateLimits  map[string]float64
+	DefaultRate float64
+}
+
+func DefaultPrewarmConfig() PrewarmConfig {
+	return PrewarmConfig{
+		Days:        DefaultPrewarmDays,
+		Interval:    DefaultPrewarmInterval,
+		RateLimits:  map[string]float64{},
+		DefaultRate: DefaultPrewarmRate,
+	}
+}
+
+type PrewarmRouteCoverage struct {
+	PrewarmRoute
+	Slots int `json:"slots"`
+	Warm  int `json:"warm"`
+}
+
+type PrewarmStatus struct {
+	Routes          int                    `json:"routes"`
+	Days            int                    `json:"days"`
+	Interval        string                 `json:"interval"`
+	Runs            int64                  `json:"runs"`
+	Running         bool                   `json:"running"`
+	LastRunStarted  *time.Time             `json:"last_run_started,omitempty"`
+	LastRunDuration int64                  `json:"last_run_duration_ms"`
+	Fetches         int64                  `json:"fetches"`
+	Failures        int64                  `json:"failures"`
+	Skipped         int64                  `json:"skipped"`
+	Slots           int                    `json:"slots"`
+	Warm            int                    `json:"warm"`
+	Coverage        float64                `json:"coverage"`
+	RouteCoverage   []PrewarmRouteCoverage `json:"route_coverage"`
+}
+
+// Prewarmer mengisi cache Aggregator secara berkala untuk rute populer, memakai jalur fetch
+// yang sama dengan pencarian biasa (providerFetch) sehingga normalisasi dan cache identik.
+type Prewarmer struct {
+	Aggregator *Aggregator
+	Config     PrewarmConfig
+
+	mu       sync.Mutex
+	running  bool
+	lastRun  time.Time
+	lastTook time.Duration
+	// warmUntil mencatat sampai kapan setiap slot (key cache provider) fresh menurut pre-warm terakhir.
+	warmUntil map[string]time.Time
+
+	runs     atomic.Int64
+	fetches  atomic.Int64
+	failures atomic.Int64
+	skipped  atomic.Int64
+}
+
+func NewPrewarmer(a *Aggregator, cfg PrewarmConfig) *Prewarmer {
+	return &Prewarmer{Aggregator: a, Config: cfg, warmUntil: map[string]time.Time{}}
+}
+
+// Start menjalankan pre-warm segera lalu setiap interval() sampai ctx dibatalkan.
+func (p *Prewarmer) Start(ctx context.Context) {
+	for _, w := range p.warnings() {
+		log.Printf("Warning: %s", w)
+	}
+
+	go func() {
+		p.Run(ctx)
+
+		ticker := time.NewTicker(p.interval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.Run(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Run menjalankan satu putaran pre-warm. Setiap provider diproses di goroutine sendiri dengan
+// rate limit masing-masing; slot yang masih fresh sampai putaran berikutnya dilewati.
+func (p *Prewarmer) Run(ctx context.Context) {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = true
+	started := time.Now()
+	p.lastRun = started
+	for key, until := range p.warmUntil {
+		if until.Before(started) {
+			delete(p.warmUntil, key)
+		}
+	}
+	p.mu.Unlock()
+
+	slots := p.slots()
+	var wg sync.WaitGroup
+	for i, provider := range p.Aggregator.Providers {
+		wg.Add(1)
+		go func(index int, name string) {
+			defer wg.Done()
+			p.warmProvider(ctx, index, name, slots)
+		}(i, provider.Name())
+	}
+	wg.Wait()
+
+	p.runs.Add(1)
+	p.mu.Lock()
+	p.running = false
+	p.lastTook = time.Since(started)
+	p.mu.Unlock()
+
+	log.Printf("prewarm run finished in %s for %d route/date slot(s)", time.Since(started).Round(time.Millisecond), len(slots))
+}
+
+func (p *Prewarmer) warmProvider(ctx context.Context, index int, name string, slots []domain.SearchCriteria) {
+	limiter := newRateLimiter(p.rate(name))
+	refreshBefore := time.Now().Add(p.interval())
+
+	for _, criteria := range slots {
+		key := providerCacheKey(name, criteria)
+		if cached, ok := p.Aggregator.FlightCache.Load(ctx, key); ok && cached.freshUntil().After(refreshBefore) {
+			p.markWarm(key, cached.freshUntil())
+			p.skipped.Add(1)
+			continue
+		}
+
+		if err := limiter.wait(ctx); err != nil {
+			return
+		}
+
+		call, _ := p.Aggregator.providerFetch(ctx, index, criteria)
+		select {
+		case <-call.done:
+			p.Aggregator.fetches.leave(call)
+		case <-ctx.Done():
+			p.Aggregator.fetches.leave(call)
+			return
+		}
+
+		p.fetches.Add(1)
+		if call.result.outcome.Status != domain.ProviderStatusOK {
+			p.failures.Add(1)
+			continue
+		}
+
+		p.markWarm(key, time.Now().Add(p.Aggregator.cacheTTL(name)))
+	}
+}
+
+func (p *Prewarmer) markWarm(key string, until time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.warmUntil[key] = until
+}
+
+// interval adalah jarak antar putaran: Config.Interval, tetapi tidak lebih lama dari TTL cache
+// provider terpendek. Entry pre-warm memakai TTL provider apa adanya, jadi putaran harus cukup
+// sering agar entry tidak kedaluwarsa di antara dua putaran.
+func (p *Prewarmer) interval() time.Duration {
+	interval := p.Config.Interval
+	for _, provider := range p.Aggregator.Providers {
+		if ttl := p.Aggregator.cacheTTL(provider.Name()); interval <= 0 || ttl < interval {
+			interval = ttl
+		}
+	}
+	return interval
+}
+
+// warnings menjelaskan konfigurasi yang membuat hasil pre-warm tidak sesuai harapan: interval
+// yang dipersingkat ke TTL provider terpendek, atau satu putaran yang lebih lama dari interval.
+func (p *Prewarmer) warnings() []string {
+	var res []string
+	interval := p.interval()
+	if interval < p.Config.Interval {
+		res = append(res, fmt.Sprintf("prewarm interval %s is longer than the shortest provider cache TTL; running every %s instead",
+			p.Config.Interval, interval))
+	}
+
+	slots := len(p.Config.Routes) * p.Config.Days
+	for _, provider := range p.Aggregator.Providers {
+		name := provider.Name()
+		if rate := p.rate(name); rate > 0 {
+			if run := time.Duration(float64(slots) / rate * float64(time.Second)); run > interval {
+				res = append(res, fmt.Sprintf("prewarm run for %s needs about %s for %d slot(s) at %.1f req/s, longer than the %s interval",
+					name, run.Round(time.Second), slots, rate, interval))
+			}
+		}
+	}
+	return res
+}
+
+func (p *Prewarmer) rate(provider string) float64 {
+	if r, ok := p.Config.RateLimits[provider]; ok && r > 0 {
+		return r
+	}
+	return p.Config.DefaultRate
+}
+
+func (p *Prewarmer) dates() []string {
+	start := time.Now().In(providers.LocationWIB)
+	if p.Config.StartDate != "" {
+		if t, err := time.Parse(dateLayout, p.Config.StartDate); err == nil {
+			start = t
+		}
+	}
+
+	dates := make([]string, p.Config.Days)
+	for i := range dates {
+		dates[i] = start.AddDate(0, 0, i).Format(dateLayout)
+	}
+	return dates
+}
+
+func (p *Prewarmer) slots() []domain.SearchCriteria {
+	var slots []domain.SearchCriteria
+	for _, date := range p.dates() {
+		for _, r := range p.Config.Routes {
+			slots = append(slots, concreteRoutes(r.criteria(date))...)
+		}
+	}
+	return slots
+}
+
+// Status melaporkan statistik run dan cakupan pre-warm: berapa slot (rute konkret × tanggal × provider)
+// yang masih fresh berdasarkan fetch pre-warm terakhir.
+func (p *Prewarmer) Status() PrewarmStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := PrewarmStatus{
+		Routes:          len(p.Config.Routes),
+		Days:            p.Config.Days,
+		Interval:        p.interval().String(),
+		Running:         p.running,
+		LastRunDuration: p.lastTook.Milliseconds(),
+	}
+	if !p.lastRun.IsZero() {
+		lastRun := p.lastRun
+		status.LastRunStarted = &lastRun
+	}
+
+	status.Runs = p.runs.Load()
+	status.Fetches = p.fetches.Load()
+	status.Failures = p.failures.Load()
+	status.Skipped = p.skipped.Load()
+
+	now := time.Now()
+	dates := p.dates()
+	for _, r := range p.Config.Routes {
+		coverage := PrewarmRouteCoverage{PrewarmRoute: r}
+		for _, date := range dates {
+			for _, criteria := range concreteRoutes(r.criteria(date)) {
+				for _, provider := range p.Aggregator.Providers {
+					coverage.Slots++
+					if p.warmUntil[providerCacheKey(provider.Name(), criteria)].After(now) {
+						coverage.Warm++
+					}
+				}
+			}
+		}
+		status.Slots += coverage.Slots
+		status.Warm += coverage.Warm
+		status.RouteCoverage = append(status.RouteCoverage, coverage)
+	}
+	if status.Slots > 0 {
+		status.Coverage = float64(status.Warm) / float64(status.Slots)
+	}
+
+	return status
+}
+
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	l := &rateLimiter{}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	now := time.Now()
+	if l.next.After(now) {
+		timer := time.NewTimer(l.next.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		now = l.next
+	}
+	l.next = now.Add(l.interval)
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPrewarmer(interval time.Duration, routes ...PrewarmRoute) (*Prewarmer, *routeRecorder) {
+	rec := &routeRecorder{}
+	a := newTestAggregator(rec.provider())
+	cfg := DefaultPrewarmConfig()
+	cfg.Routes = routes
+	cfg.StartDate = "2025-12-15"
+	cfg.Days = 2
+	cfg.Interval = interval
+	cfg.DefaultRate = 0
+	return NewPrewarmer(a, cfg), rec
+}
+
+func TestPrewarmKeepsProviderTTL(t *testing.T) {
+	p, rec := testPrewarmer(10*time.Minute, PrewarmRoute{Origin: "CGK", Destination: "DPS", CabinClass: "economy"})
+	p.Run(context.Background())
+
+	if got := len(rec.requested()); got != 2 {
+		t.Fatalf("fetched %d slots, want 2", got)
+	}
+	for _, criteria := range p.slots() {
+		cached, ok := p.Aggregator.FlightCache.Load(context.Background(), providerCacheKey("Stub", criteria))
+		if !ok {
+			t.Fatalf("slot %s not cached", criteria.DepartureDate)
+		}
+		if cached.TTL != CacheExpiration {
+			t.Errorf("slot %s TTL = %v, want the %v provider TTL", criteria.DepartureDate, cached.TTL, CacheExpiration)
+		}
+	}
+	if status := p.Status(); status.Coverage != 1 || status.Fetches != 2 {
+		t.Errorf("status = %+v, want full coverage after 2 fetches", status)
+	}
+}
+
+func TestPrewarmIntervalIsCappedByShortestProviderTTL(t *testing.T) {
+	p, _ := testPrewarmer(10*time.Minute, PrewarmRoute{Origin: "CGK", Destination: "DPS", CabinClass: "economy"})
+	if got := p.interval(); got != CacheExpiration {
+		t.Errorf("interval = %v, want the %v provider TTL", got, CacheExpiration)
+	}
+	if status := p.Status(); status.Interval != CacheExpiration.String() {
+		t.Errorf("status interval = %s, want %s", status.Interval, CacheExpiration)
+	}
+
+	p.Aggregator.CacheTTLs["Stub"] = time.Hour
+	if got := p.interval(); got != 10*time.Minute {
+		t.Errorf("interval = %v, want the configured 10m", got)
+	}
+}
+
+func TestPrewarmSkipsSlotsFreshUntilTheNextRun(t *testing.T) {
+	p, rec := testPrewarmer(time.Minute, PrewarmRoute{Origin: "CGK", Destination: "DPS", CabinClass: "economy"})
+	p.Aggregator.CacheTTLs["Stub"] = time.Hour
+
+	p.Run(context.Background())
+	p.Run(context.Background())
+	if got := len(rec.requested()); got != 2 {
+		t.Errorf("fetched %d times over two runs, want 2", got)
+	}
+	if status := p.Status(); status.Skipped != 2 {
+		t.Errorf("skipped = %d, want 2", status.Skipped)
+	}
+}
+
+func TestPrewarmExpandsMetroRoutes(t *testing.T) {
+	p, rec := testPrewarmer(time.Minute, PrewarmRoute{Origin: "JKT", Destination: "DPS", CabinClass: "economy"})
+	p.Run(context.Background())
+
+	got := rec.requested()
+	if len(got) != 4 || got[0] != "CGK-DPS" || got[3] != "HLP-DPS" {
+		t.Errorf("requested = %v, want CGK-DPS and HLP-DPS for both days", got)
+	}
+	if status := p.Status(); status.Slots != 4 || status.Warm != 4 {
+		t.Errorf("status = %+v, want 4 warm slots", status)
+	}
+}
+
+func TestPrewarmWarnings(t *testing.T) {
+	route := PrewarmRoute{Origin: "CGK", Destination: "DPS", CabinClass: "economy"}
+
+	p, _ := testPrewarmer(time.Minute, route)
+	if w := p.warnings(); len(w) != 0 {
+		t.Errorf("warnings = %v, want none when the interval fits the TTL", w)
+	}
+
+	p, _ = testPrewarmer(10*time.Minute, route)
+	if w := p.warnings(); len(w) != 1 || !strings.Contains(w[0], "running every 1m0s instead") {
+		t.Errorf("warnings = %v, want a shortened interval warning", w)
+	}
+
+	p, _ = testPrewarmer(time.Minute, route)
+	p.Config.Days = 30
+	p.Config.DefaultRate = 0.2
+	if w := p.warnings(); len(w) != 1 || !strings.Contains(w[0], "longer than the 1m0s interval") {
+		t.Errorf("warnings = %v, want a run-length warning", w)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// stubProvider adalah provider palsu untuk test; search dipanggil untuk setiap permintaan.
+type stubProvider struct {
+	name   string
+	search func(ctx context.Context, criteria domain.SearchCriteria) ([]domain.UnifiedFlight, error)
+	calls  atomic.Int32
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) Search(ctx context.Context, criteria domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	p.calls.Add(1)
+	return p.search(ctx, criteria)
+}
+
+func stubFlight(id, origin, destination string, departure time.Time, minutes int, price float64) domain.UnifiedFlight {
+	arrival := departure.Add(time.Duration(minutes) * time.Minute)
+	return domain.UnifiedFlight{
+		ID:           id,
+		Provider:     "Stub",
+		Airline:      domain.AirlineInfo{Name: "Stub Air", Code: "SA"},
+		FlightNumber: id,
+		Departure: domain.FlightPoint{
+			Airport:   origin,
+			Datetime:  departure.Format(time.RFC3339),
+			Timestamp: departure.Unix(),
+			TimeOfDay: departure,
+		},
+		Arrival: domain.FlightPoint{
+			Airport:   destination,
+			Datetime:  arrival.Format(time.RFC3339),
+			Timestamp: arrival.Unix(),
+			TimeOfDay: arrival,
+		},
+		Duration:       domain.DurationInfo{TotalMinutes: minutes, Formatted: formatDuration(minutes)},
+		Price:          domain.PriceInfo{Amount: price, Currency: "IDR"},
+		AvailableSeats: 9,
+		CabinClass:     "economy",
+		IsValid:        true,
+	}
+}
+
+func newTestAggregator(ps ...*stubProvider) *Aggregator {
+	list := make([]providers.ProviderInterface, len(ps))
+	for i, p := range ps {
+		list[i] = p
+	}
+	cache := NewLRUCache(LRUConfig{MaxEntries: 1000})
+	a := NewAggregatorWithCache(list, cache)
+	a.Hedging.Enabled = false
+	return a
+}
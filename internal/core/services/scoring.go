@@ -0,0 +1,241 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const DefaultScoringProfile = "default"
+
+// DefaultAirlineRating dipakai untuk maskapai yang tidak ada di AirlineRatings.
+const DefaultAirlineRating = 3.5
+
+const maxAirlineRating = 5.0
+
+// ScoringProfile berisi bobot best-value. Skor adalah jumlah penalti sehingga lebih kecil lebih baik:
+// Price per Rp1.000, Duration per menit, Stops per transit, DepartureTime per jam di luar jendela
+// keberangkatan yang disukai, Baggage jika tidak termasuk bagasi checked, AirlineRating per poin
+// rating di bawah 5, sedangkan Amenities mengurangi skor per fasilitas.
+type ScoringProfile struct {
+	Name          string
+	Price         float64
+	Duration      float64
+	Stops         float64
+	DepartureTime float64
+	Baggage       float64
+	Amenities     float64
+	AirlineRating float64
+	// PreferredDepFrom dan PreferredDepTo dalam menit sejak tengah malam (waktu lokal bandara asal).
+	PreferredDepFrom int
+	PreferredDepTo   int
+}
+
+// ScoringProfiles adalah profil bernama yang bisa dipilih lewat scoringProfile. Profil default
+// mempertahankan rumus lama: price/1000 + menit*5 + transit*5000.
+var ScoringProfiles = map[string]ScoringProfile{
+	DefaultScoringProfile: {
+		Name:     DefaultScoringProfile,
+		Price:    1,
+		Duration: 5,
+		Stops:    5000,
+	},
+	"budget": {
+		Name:     "budget",
+		Price:    2,
+		Duration: 1,
+		Stops:    1000,
+	},
+	"business": {
+		Name:             "business",
+		Price:            0.2,
+		Duration:         10,
+		Stops:            8000,
+		DepartureTime:    300,
+		Amenities:        300,
+		AirlineRating:    1500,
+		PreferredDepFrom: 6 * 60,
+		PreferredDepTo:   10 * 60,
+	},
+	"family": {
+		Name:             "family",
+		Price:            1,
+		Duration:         3,
+		Stops:            8000,
+		DepartureTime:    400,
+		Baggage:          3000,
+		Amenities:        200,
+		PreferredDepFrom: 8 * 60,
+		PreferredDepTo:   18 * 60,
+	},
+}
+
+// AirlineRatings adalah rating maskapai (skala 1-5) yang dipakai komponen airline_rating.
+var AirlineRatings = map[string]float64{
+	"GA": 4.5,
+	"ID": 4.0,
+	"QZ": 3.8,
+	"JT": 3.3,
+}
+
+// ResolveScoring memilih profil scoring dari kriteria lalu menerapkan override bobot per request.
+func ResolveScoring(profileName string, weights *domain.ScoringWeights) (ScoringProfile, error) {
+	if profileName == "" {
+		profileName = DefaultScoringProfile
+	}
+	profile, ok := ScoringProfiles[profileName]
+	if !ok {
+		return ScoringProfile{}, fmt.Errorf("unknown scoring profile %q", profileName)
+	}
+	if weights == nil {
+		return profile, nil
+	}
+
+	overrides := []struct {
+		name  string
+		value *float64
+		dst   *float64
+	}{
+		{"price", weights.Price, &profile.Price},
+		{"duration", weights.Duration, &profile.Duration},
+		{"stops", weights.Stops, &profile.Stops},
+		{"departureTime", weights.DepartureTime, &profile.DepartureTime},
+		{"baggage", weights.Baggage, &profile.Baggage},
+		{"amenities", weights.Amenities, &profile.Amenities},
+		{"airlineRating", weights.AirlineRating, &profile.AirlineRating},
+	}
+	for _, o := range overrides {
+		if o.value == nil {
+			continue
+		}
+		if *o.value < 0 || math.IsNaN(*o.value) || math.IsInf(*o.value, 0) {
+			return ScoringProfile{}, fmt.Errorf("scoring weight %s must be a non-negative number", o.name)
+		}
+		*o.dst = *o.value
+	}
+
+	if weights.PreferredDepFrom != nil {
+		m, err := minutesOfDay(*weights.PreferredDepFrom)
+		if err != nil {
+			return ScoringProfile{}, fmt.Errorf("preferredDepFrom must use the HH:MM format")
+		}
+		profile.PreferredDepFrom = m
+	}
+	if weights.PreferredDepTo != nil {
+		m, err := minutesOfDay(*weights.PreferredDepTo)
+		if err != nil {
+			return ScoringProfile{}, fmt.Errorf("preferredDepTo must use the HH:MM format")
+		}
+		profile.PreferredDepTo = m
+	}
+
+	// Tanpa jendela keberangkatan, bobot departureTime tidak pernah berpengaruh.
+	if weights.DepartureTime != nil && profile.DepartureTime > 0 && profile.PreferredDepFrom == profile.PreferredDepTo {
+		return ScoringProfile{}, fmt.Errorf("scoring weight departureTime requires preferredDepFrom and preferredDepTo")
+	}
+
+	return profile, nil
+}
+
+// scoringFor mengembalikan profil scoring untuk kriteria; kriteria tidak valid (sudah ditolak
+// di handler) jatuh ke profil default.
+func scoringFor(c domain.SearchCriteria) ScoringProfile {
+	profile, err := ResolveScoring(c.ScoringProfile, c.ScoringWeights)
+	if err != nil {
+		return ScoringProfiles[DefaultScoringProfile]
+	}
+	return profile
+}
+
+func minutesOfDay(s string) (int, error) {
+	t, err := time.Parse(filterTimeLayout, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// calculateBestValue mengisi Score dan ScoreBreakdown setiap penerbangan berdasarkan profil.
+func calculateBestValue(flights []domain.UnifiedFlight, profile ScoringProfile) []domain.UnifiedFlight {
+	for i := range flights {
+		f := &flights[i]
+		b := profile.breakdown(f)
+		f.Score = b.Total
+		f.ScoreBreakdown = &b
+	}
+	return flights
+}
+
+func (p ScoringProfile) breakdown(f *domain.UnifiedFlight) domain.ScoreBreakdown {
+	b := domain.ScoreBreakdown{
+		Profile:  p.Name,
+		Price:    f.Price.Amount / 1000.0 * p.Price,
+		Duration: float64(f.Duration.TotalMinutes) * p.Duration,
+		Stops:    float64(f.Stops) * p.Stops,
+	}
+
+	if p.DepartureTime > 0 {
+		b.DepartureTime = p.hoursOutsideWindow(f.Departure.TimeOfDay) * p.DepartureTime
+	}
+	if p.Baggage > 0 && !includesCheckedBaggage(f.Baggage) {
+		b.Baggage = p.Baggage
+	}
+	if p.Amenities > 0 {
+		b.Amenities = -float64(len(f.Amenities)) * p.Amenities
+	}
+	if p.AirlineRating > 0 {
+		b.AirlineRating = (maxAirlineRating - airlineRating(f.Airline.Code)) * p.AirlineRating
+	}
+
+	b.Total = b.Price + b.Duration + b.Stops + b.DepartureTime + b.Baggage + b.Amenities + b.AirlineRating
+	return b
+}
+
+// hoursOutsideWindow menghitung jarak (jam) waktu keberangkatan dari jendela yang disukai.
+func (p ScoringProfile) hoursOutsideWindow(t time.Time) float64 {
+	if t.IsZero() || p.PreferredDepFrom == p.PreferredDepTo {
+		return 0
+	}
+
+	m := t.Hour()*60 + t.Minute()
+	from, to := p.PreferredDepFrom, p.PreferredDepTo
+	if from < to {
+		if m >= from && m <= to {
+			return 0
+		}
+	} else if m >= from || m <= to {
+		// Jendela melewati tengah malam, mis. 22:00-02:00.
+		return 0
+	}
+
+	return float64(min(circularDistance(m, from), circularDistance(m, to))) / 60.0
+}
+
+func circularDistance(a, b int) int {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	return min(d, 24*60-d)
+}
+
+func includesCheckedBaggage(b domain.BaggageInfo) bool {
+	checked := strings.ToLower(strings.TrimSpace(b.Checked))
+	if checked == "" {
+		return false
+	}
+	if checked == "none" || strings.Contains(checked, "fee") || strings.Contains(checked, "not included") {
+		return false
+	}
+	// "0 piece(s)" atau "0 kg" berarti tidak ada bagasi checked.
+	return !strings.HasPrefix(checked, "0 ") && !strings.HasPrefix(checked, "0kg")
+}
+
+func airlineRating(code string) float64 {
+	if r, ok := AirlineRatings[code]; ok {
+		return r
+	}
+	return DefaultAirlineRating
+}
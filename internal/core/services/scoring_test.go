@@ -0,0 +1,120 @@
+package services
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func float(v float64) *float64 { return &v }
+
+func text(v string) *string { return &v }
+
+func TestScoreBreakdownSumsToScore(t *testing.T) {
+	day := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	direct := stubFlight("F1", "CGK", "DPS", day.Add(5*time.Hour), 110, 1200000)
+	direct.Airline.Code = "GA"
+	direct.Amenities = []string{"wifi", "meal"}
+	direct.Baggage = domain.BaggageInfo{Checked: "20 kg"}
+	connecting := stubFlight("F2", "CGK", "DPS", day.Add(21*time.Hour), 300, 700000)
+	connecting.Stops = 1
+	connecting.Baggage = domain.BaggageInfo{Checked: "not included"}
+
+	for name := range ScoringProfiles {
+		profile, err := ResolveScoring(name, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, f := range calculateBestValue([]domain.UnifiedFlight{direct, connecting}, profile) {
+			b := f.ScoreBreakdown
+			sum := b.Price + b.Duration + b.Stops + b.DepartureTime + b.Baggage + b.Amenities + b.AirlineRating
+			if b.Profile != name || math.Abs(sum-f.Score) > 1e-9 || b.Total != f.Score {
+				t.Errorf("%s %s: breakdown %+v sums to %v, score %v", name, f.ID, *b, sum, f.Score)
+			}
+		}
+	}
+}
+
+func TestScoreBreakdownComponents(t *testing.T) {
+	day := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	f := stubFlight("F1", "CGK", "DPS", day.Add(5*time.Hour), 120, 1000000)
+	f.Stops = 1
+	f.Airline.Code = "GA"
+	f.Amenities = []string{"wifi", "meal"}
+
+	tests := []struct {
+		profile string
+		want    domain.ScoreBreakdown
+	}{
+		{"default", domain.ScoreBreakdown{Price: 1000, Duration: 600, Stops: 5000}},
+		{"budget", domain.ScoreBreakdown{Price: 2000, Duration: 120, Stops: 1000}},
+		// 05:00 satu jam sebelum jendela 06:00-10:00, rating GA 4.5.
+		{"business", domain.ScoreBreakdown{Price: 200, Duration: 1200, Stops: 8000, DepartureTime: 300, Amenities: -600, AirlineRating: 750}},
+		// 05:00 tiga jam sebelum jendela 08:00-18:00, tanpa bagasi checked.
+		{"family", domain.ScoreBreakdown{Price: 1000, Duration: 360, Stops: 8000, DepartureTime: 1200, Baggage: 3000, Amenities: -400}},
+	}
+
+	for _, tt := range tests {
+		profile, err := ResolveScoring(tt.profile, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.profile, err)
+		}
+		got := profile.breakdown(&f)
+		want := tt.want
+		want.Profile = tt.profile
+		want.Total = want.Price + want.Duration + want.Stops + want.DepartureTime + want.Baggage + want.Amenities + want.AirlineRating
+		if got != want {
+			t.Errorf("%s: breakdown = %+v, want %+v", tt.profile, got, want)
+		}
+	}
+}
+
+func TestResolveScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		weights *domain.ScoringWeights
+		wantErr string
+		check   func(p ScoringProfile) bool
+	}{
+		{name: "empty profile falls back to default", check: func(p ScoringProfile) bool { return p == ScoringProfiles[DefaultScoringProfile] }},
+		{name: "named profile", profile: "business", check: func(p ScoringProfile) bool { return p == ScoringProfiles["business"] }},
+		{
+			name: "weights override the profile", profile: "family",
+			weights: &domain.ScoringWeights{Price: float(2), PreferredDepFrom: text("09:00"), PreferredDepTo: text("15:00")},
+			check: func(p ScoringProfile) bool {
+				return p.Price == 2 && p.Duration == 3 && p.PreferredDepFrom == 9*60 && p.PreferredDepTo == 15*60
+			},
+		},
+		{
+			name:    "departure weight with a window on the default profile",
+			weights: &domain.ScoringWeights{DepartureTime: float(100), PreferredDepFrom: text("06:00"), PreferredDepTo: text("09:00")},
+			check:   func(p ScoringProfile) bool { return p.DepartureTime == 100 },
+		},
+		{name: "zero departure weight without a window", weights: &domain.ScoringWeights{DepartureTime: float(0)}, check: func(p ScoringProfile) bool { return p.DepartureTime == 0 }},
+		{name: "unknown profile", profile: "luxury", wantErr: "unknown scoring profile"},
+		{name: "negative weight", weights: &domain.ScoringWeights{Stops: float(-1)}, wantErr: "stops must be a non-negative number"},
+		{name: "NaN weight", weights: &domain.ScoringWeights{Price: float(math.NaN())}, wantErr: "price must be a non-negative number"},
+		{name: "bad window time", profile: "business", weights: &domain.ScoringWeights{PreferredDepFrom: text("6am")}, wantErr: "preferredDepFrom"},
+		{name: "departure weight without a window", weights: &domain.ScoringWeights{DepartureTime: float(100)}, wantErr: "departureTime requires"},
+	}
+
+	for _, tt := range tests {
+		p, err := ResolveScoring(tt.profile, tt.weights)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !tt.check(p) {
+			t.Errorf("%s: got %+v", tt.name, p)
+		}
+	}
+}
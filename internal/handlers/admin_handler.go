@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bookcabin-test/internal/core/services"
+	"encoding/json"
+	"net/http"
+)
+
+type AdminHandlers struct {
+	AggregatorService *services.Aggregator
+	Prewarmer         *services.Prewarmer
+}
+
+func NewAdminHandlers(svc *services.Aggregator, prewarmer *services.Prewarmer) *AdminHandlers {
+	return &AdminHandlers{AggregatorService: svc, Prewarmer: prewarmer}
+}
+
+func (s *AdminHandlers) CacheStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"cache":      s.AggregatorService.CacheStats(),
+		"coalescing": s.AggregatorService.CoalescingStats(),
+	})
+}
+
+func (s *AdminHandlers) CircuitBreakers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"circuit_breakers": s.AggregatorService.BreakerStates(),
+	})
+}
+
+func (s *AdminHandlers) Prewarm(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.Prewarmer == nil {
+		json.NewEncoder(w).Encode(map[string]any{"enabled": false})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled": true,
+		"prewarm": s.Prewarmer.Status(),
+	})
+}
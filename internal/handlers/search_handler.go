@@ -2,10 +2,19 @@ package handlers
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/core/filterexpr"
 	"bookcabin-test/internal/core/services"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
+)
+
+const (
+	maxMultiCityLegs      = 6
+	maxFlexibleWindowDays = 7
 )
 
 type SearchHandlers struct {
@@ -30,16 +39,82 @@ func (s *SearchHandlers) SearchFlight(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if criteria.Limit < 0 || criteria.Limit > services.MaxPageLimit {
+		http.Error(w, fmt.Sprintf("Bad Request: Limit must be between 0 and %d (0 disables pagination).", services.MaxPageLimit), http.StatusBadRequest)
+		return
+	}
+
+	// Halaman berikutnya cukup dengan cursor; kriteria lain diambil dari snapshot.
+	if criteria.Cursor != "" {
+		resp, err := s.AggregatorService.NextPage(r.Context(), criteria.Cursor, criteria.Limit)
+		switch {
+		case errors.Is(err, services.ErrCursorExpired):
+			http.Error(w, "Gone: Cursor has expired, please repeat the search.", http.StatusGone)
+			return
+		case err != nil:
+			http.Error(w, "Bad Request: Invalid cursor.", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
 	if criteria.Origin == "" || criteria.Destination == "" || criteria.DepartureDate == "" {
 		http.Error(w, "Bad Request: Origin, Destination, and DepartureDate are required.", http.StatusBadRequest)
 		return
 	}
 
+	if criteria.Limit > 0 && criteria.ReturnDate != nil && *criteria.ReturnDate != "" {
+		http.Error(w, "Bad Request: Limit is not supported for round-trip searches.", http.StatusBadRequest)
+		return
+	}
+
+	if criteria.NearbyRadiusKm != nil && (*criteria.NearbyRadiusKm <= 0 || *criteria.NearbyRadiusKm > services.MaxNearbyRadiusKm) {
+		http.Error(w, fmt.Sprintf("Bad Request: NearbyRadiusKm must be greater than 0 and at most %.0f.", services.MaxNearbyRadiusKm), http.StatusBadRequest)
+		return
+	}
+
+	if criteria.MinSelfTransferMinutes != nil && *criteria.MinSelfTransferMinutes < 0 {
+		http.Error(w, "Bad Request: MinSelfTransferMinutes must not be negative.", http.StatusBadRequest)
+		return
+	}
+
+	if criteria.DedupMode != "" && criteria.DedupMode != services.DedupModeMerge && criteria.DedupMode != services.DedupModeRaw {
+		http.Error(w, "Bad Request: DedupMode must be either merge or raw.", http.StatusBadRequest)
+		return
+	}
+
+	if criteria.ReturnDate != nil && *criteria.ReturnDate != "" && *criteria.ReturnDate < criteria.DepartureDate {
+		http.Error(w, "Bad Request: ReturnDate must not be before DepartureDate.", http.StatusBadRequest)
+		return
+	}
+
+	if !compileFilterExpression(w, &criteria.Filters) {
+		return
+	}
+
+	if _, err := services.ResolveScoring(criteria.ScoringProfile, criteria.ScoringWeights); err != nil {
+		http.Error(w, "Bad Request: Invalid scoring - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if criteria.SortBy == "" {
 		criteria.SortBy = "best_value"
 	}
 
-	resp := s.AggregatorService.SearchFlights(criteria)
+	sortSpec, err := services.ParseSort(criteria.SortBy, criteria.Sort)
+	if err != nil {
+		http.Error(w, "Bad Request: Invalid sort - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	criteria.Sort = sortSpec
+
+	var resp domain.SearchResponse
+	if criteria.Limit > 0 {
+		resp = s.AggregatorService.SearchFirstPage(r.Context(), criteria)
+	} else {
+		resp = s.AggregatorService.SearchFlights(r.Context(), criteria)
+	}
 
 	if len(resp.Flights) == 0 && resp.Metadata.ProvidersFailed > 0 {
 		log.Printf("Warning: %d providers failed.", resp.Metadata.ProvidersFailed)
@@ -47,3 +122,156 @@ func (s *SearchHandlers) SearchFlight(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(resp)
 }
+
+func (s *SearchHandlers) SearchMultiCity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var criteria domain.MultiCitySearchCriteria
+	if err := json.NewDecoder(r.Body).Decode(&criteria); err != nil {
+		http.Error(w, "Bad Request: Invalid JSON or format - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(criteria.Legs) < 2 || len(criteria.Legs) > maxMultiCityLegs {
+		http.Error(w, fmt.Sprintf("Bad Request: Multi-city search requires between 2 and %d legs.", maxMultiCityLegs), http.StatusBadRequest)
+		return
+	}
+
+	for i, leg := range criteria.Legs {
+		if leg.Origin == "" || leg.Destination == "" || leg.DepartureDate == "" {
+			http.Error(w, fmt.Sprintf("Bad Request: Leg %d requires Origin, Destination, and DepartureDate.", i+1), http.StatusBadRequest)
+			return
+		}
+		if i > 0 && leg.DepartureDate < criteria.Legs[i-1].DepartureDate {
+			http.Error(w, fmt.Sprintf("Bad Request: Leg %d departs before leg %d.", i+1, i), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if criteria.MinConnectionMinutes != nil && *criteria.MinConnectionMinutes < 0 {
+		http.Error(w, "Bad Request: MinConnectionMinutes must not be negative.", http.StatusBadRequest)
+		return
+	}
+
+	if !compileFilterExpression(w, &criteria.Filters) {
+		return
+	}
+
+	if _, err := services.ResolveScoring(criteria.ScoringProfile, criteria.ScoringWeights); err != nil {
+		http.Error(w, "Bad Request: Invalid scoring - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if criteria.SortBy == "" {
+		criteria.SortBy = "best_value"
+	}
+
+	sortSpec, err := services.ParseSort(criteria.SortBy, criteria.Sort)
+	if err != nil {
+		http.Error(w, "Bad Request: Invalid sort - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	criteria.Sort = sortSpec
+
+	resp := s.AggregatorService.SearchMultiCity(r.Context(), criteria)
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *SearchHandlers) SearchFlexible(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var criteria domain.FlexibleSearchCriteria
+	if err := json.NewDecoder(r.Body).Decode(&criteria); err != nil {
+		http.Error(w, "Bad Request: Invalid JSON or format - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if criteria.Origin == "" || criteria.Destination == "" || criteria.DepartureDate == "" {
+		http.Error(w, "Bad Request: Origin, Destination, and DepartureDate are required.", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", criteria.DepartureDate); err != nil {
+		http.Error(w, "Bad Request: DepartureDate must use the YYYY-MM-DD format.", http.StatusBadRequest)
+		return
+	}
+
+	if criteria.WindowDays < 0 || criteria.WindowDays > maxFlexibleWindowDays {
+		http.Error(w, fmt.Sprintf("Bad Request: WindowDays must be between 0 and %d.", maxFlexibleWindowDays), http.StatusBadRequest)
+		return
+	}
+
+	if criteria.NearbyRadiusKm != nil && (*criteria.NearbyRadiusKm <= 0 || *criteria.NearbyRadiusKm > services.MaxNearbyRadiusKm) {
+		http.Error(w, fmt.Sprintf("Bad Request: NearbyRadiusKm must be greater than 0 and at most %.0f.", services.MaxNearbyRadiusKm), http.StatusBadRequest)
+		return
+	}
+
+	if criteria.MinSelfTransferMinutes != nil && *criteria.MinSelfTransferMinutes < 0 {
+		http.Error(w, "Bad Request: MinSelfTransferMinutes must not be negative.", http.StatusBadRequest)
+		return
+	}
+
+	if !compileFilterExpression(w, &criteria.Filters) {
+		return
+	}
+
+	if _, err := services.ResolveScoring(criteria.ScoringProfile, criteria.ScoringWeights); err != nil {
+		http.Error(w, "Bad Request: Invalid scoring - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if criteria.SortBy == "" {
+		criteria.SortBy = "best_value"
+	}
+
+	sortSpec, err := services.ParseSort(criteria.SortBy, criteria.Sort)
+	if err != nil {
+		http.Error(w, "Bad Request: Invalid sort - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	criteria.Sort = sortSpec
+
+	resp := s.AggregatorService.SearchFlexible(r.Context(), criteria)
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// compileFilterExpression meng-compile ekspresi filter sekali dan menyimpannya di filters.Compiled.
+// Jika tidak valid, menulis 400 berisi JSON yang menunjuk posisi dan token penyebabnya.
+func compileFilterExpression(w http.ResponseWriter, filters *domain.FilterOptions) bool {
+	if filters.Expression == "" {
+		return true
+	}
+
+	program, err := filterexpr.Compile(filters.Expression)
+	if err == nil {
+		filters.Compiled = program
+		return true
+	}
+
+	var syntaxErr *filterexpr.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		syntaxErr = &filterexpr.SyntaxError{Message: err.Error()}
+	}
+
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":      "Bad Request: Invalid filter expression.",
+		"message":    syntaxErr.Message,
+		"position":   syntaxErr.Position,
+		"token":      syntaxErr.Token,
+		"expression": filters.Expression,
+	})
+	return false
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/core/services"
+	"bookcabin-test/internal/platform/providers"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fixedProvider struct{}
+
+func (fixedProvider) Name() string { return "Fixed" }
+
+func (fixedProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	day, _ := time.Parse("2006-01-02", c.DepartureDate)
+	var res []domain.UnifiedFlight
+	for i := range 12 {
+		dep := day.Add(time.Duration(6+i) * time.Hour)
+		arr := dep.Add(90 * time.Minute)
+		res = append(res, domain.UnifiedFlight{
+			ID:             fmt.Sprintf("FX%02d", i),
+			Airline:        domain.AirlineInfo{Name: "Fixed Air", Code: "FX"},
+			FlightNumber:   fmt.Sprintf("FX%02d", i),
+			Departure:      domain.FlightPoint{Airport: c.Origin, Timestamp: dep.Unix(), TimeOfDay: dep},
+			Arrival:        domain.FlightPoint{Airport: c.Destination, Timestamp: arr.Unix(), TimeOfDay: arr},
+			Duration:       domain.DurationInfo{TotalMinutes: 90},
+			Price:          domain.PriceInfo{Amount: float64(400000 + i*10000), Currency: "IDR"},
+			AvailableSeats: 9,
+			CabinClass:     "economy",
+			IsValid:        true,
+		})
+	}
+	return res, nil
+}
+
+func newTestSearchHandlers() *SearchHandlers {
+	agg := services.NewAggregatorWithCache([]providers.ProviderInterface{fixedProvider{}}, services.NewLRUCache(services.LRUConfig{MaxEntries: 100}))
+	return NewSearchHandlers(agg)
+}
+
+func postSearch(h *SearchHandlers, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.SearchFlight(rec, httptest.NewRequest(http.MethodPost, "/v1/search", strings.NewReader(body)))
+	return rec
+}
+
+func firstPageCursor(t *testing.T, h *SearchHandlers) string {
+	t.Helper()
+	rec := postSearch(h, `{"origin":"CGK","destination":"DPS","departureDate":"2025-12-15","passengers":1,"cabinClass":"economy","sortBy":"price_asc","limit":5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first page status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp domain.SearchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Flights) != 5 || resp.NextCursor == "" {
+		t.Fatalf("first page has %d flights and cursor %q", len(resp.Flights), resp.NextCursor)
+	}
+	return resp.NextCursor
+}
+
+func TestSearchFlightCursor(t *testing.T) {
+	h := newTestSearchHandlers()
+	cursor := firstPageCursor(t, h)
+
+	rec := postSearch(h, `{"cursor":"`+cursor+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("next page status = %d: %s", rec.Code, rec.Body)
+	}
+
+	// Replika lain (cache kosong) tidak punya snapshot-nya lagi.
+	if rec := postSearch(newTestSearchHandlers(), `{"cursor":"`+cursor+`"}`); rec.Code != http.StatusGone {
+		t.Errorf("expired cursor status = %d, want 410", rec.Code)
+	}
+
+	if rec := postSearch(h, `{"cursor":"not-a-cursor"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed cursor status = %d, want 400", rec.Code)
+	}
+	if rec := postSearch(h, `{"cursor":"`+cursor+`","limit":500}`); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "between 0 and 100") {
+		t.Errorf("oversized limit status = %d: %s, want 400", rec.Code, rec.Body)
+	}
+	if rec := postSearch(h, `{"origin":"CGK","destination":"DPS","departureDate":"2025-12-15","limit":-1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative limit status = %d, want 400", rec.Code)
+	}
+	if rec := postSearch(h, `{"origin":"CGK","destination":"DPS","departureDate":"2025-12-15","limit":0}`); rec.Code != http.StatusOK {
+		t.Errorf("zero limit status = %d, want 200 without pagination", rec.Code)
+	}
+}
+
+func TestSearchFlightFilterExpression(t *testing.T) {
+	h := newTestSearchHandlers()
+
+	rec := postSearch(h, `{"origin":"CGK","destination":"DPS","departureDate":"2025-12-15","filters":{"expression":"price < 450000"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid expression status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp domain.SearchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Flights) != 5 {
+		t.Errorf("got %d flights under 450000, want 5", len(resp.Flights))
+	}
+
+	rec = postSearch(h, `{"origin":"CGK","destination":"DPS","departureDate":"2025-12-15","filters":{"expression":"price = 1"}}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid expression status = %d, want 400", rec.Code)
+	}
+	var body struct {
+		Position int    `json:"position"`
+		Token    string `json:"token"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Position != 7 || body.Token != "=" {
+		t.Errorf("got position %d token %q, want 7 %q", body.Position, body.Token, "=")
+	}
+}
+
+func TestSearchMultiCityRejectsNegativeConnection(t *testing.T) {
+	h := newTestSearchHandlers()
+	body := `{"legs":[{"origin":"CGK","destination":"DPS","departureDate":"2025-12-15"},{"origin":"DPS","destination":"SUB","departureDate":"2025-12-15"}],"minConnectionMinutes":-600}`
+
+	rec := httptest.NewRecorder()
+	h.SearchMultiCity(rec, httptest.NewRequest(http.MethodPost, "/v1/search/multi-city", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSearchFlightRejectsNegativeSelfTransferConnection(t *testing.T) {
+	rec := postSearch(newTestSearchHandlers(), `{"origin":"CGK","destination":"DPS","departureDate":"2025-12-15","includeSelfTransfer":true,"minSelfTransferMinutes":-600}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
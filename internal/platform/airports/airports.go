@@ -0,0 +1,128 @@
+package airports
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+const earthRadiusKm = 6371.0
+
+//go:embed airports.json
+var airportsData []byte
+
+type Airport struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Country   string  `json:"country"`
+	MetroCode string  `json:"metro_code,omitempty"`
+	Timezone  string  `json:"timezone"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	location *time.Location
+}
+
+var registry = mustLoad(airportsData)
+
+// mustLoad mem-parsing data bandara yang di-embed. Data ini bagian dari binary,
+// jadi kegagalan parsing adalah bug build dan langsung panic.
+func mustLoad(data []byte) map[string]Airport {
+	var list []Airport
+	if err := json.Unmarshal(data, &list); err != nil {
+		panic(fmt.Sprintf("airports: invalid embedded data: %v", err))
+	}
+
+	res := make(map[string]Airport, len(list))
+	for _, a := range list {
+		loc, err := time.LoadLocation(a.Timezone)
+		if err != nil {
+			panic(fmt.Sprintf("airports: invalid timezone %q for %s: %v", a.Timezone, a.Code, err))
+		}
+		a.location = loc
+		res[a.Code] = a
+	}
+	return res
+}
+
+func (a Airport) Location() *time.Location {
+	return a.location
+}
+
+func Lookup(code string) (Airport, bool) {
+	a, ok := registry[strings.ToUpper(code)]
+	return a, ok
+}
+
+// Expand mengembalikan semua kode bandara yang dilayani oleh code. Kode metro (mis. JKT)
+// diperluas ke semua bandaranya, dan jika includeNearby aktif, bandara lain dalam
+// radiusKm dari salah satu bandara tersebut ikut ditambahkan. Bandara dari code sendiri
+// selalu di depan (urut alfabet), diikuti bandara terdekat menurut jaraknya.
+func Expand(code string, includeNearby bool, radiusKm float64) []string {
+	code = strings.ToUpper(code)
+	var base []string
+
+	if _, ok := registry[code]; ok {
+		base = append(base, code)
+	} else {
+		for c, a := range registry {
+			if a.MetroCode == code {
+				base = append(base, c)
+			}
+		}
+		if len(base) == 0 {
+			return []string{code}
+		}
+		sort.Strings(base)
+	}
+
+	if !includeNearby {
+		return base
+	}
+
+	distances := map[string]float64{}
+	for c, candidate := range registry {
+		if slices.Contains(base, c) {
+			continue
+		}
+		nearest := math.Inf(1)
+		for _, b := range base {
+			nearest = math.Min(nearest, DistanceKm(registry[b], candidate))
+		}
+		if nearest <= radiusKm {
+			distances[c] = nearest
+		}
+	}
+
+	nearby := make([]string, 0, len(distances))
+	for c := range distances {
+		nearby = append(nearby, c)
+	}
+	sort.Slice(nearby, func(i, j int) bool {
+		if distances[nearby[i]] != distances[nearby[j]] {
+			return distances[nearby[i]] < distances[nearby[j]]
+		}
+		return nearby[i] < nearby[j]
+	})
+	return append(base, nearby...)
+}
+
+func DistanceKm(a, b Airport) float64 {
+	lat1, lat2 := toRadians(a.Latitude), toRadians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := toRadians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
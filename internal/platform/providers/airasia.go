@@ -2,39 +2,38 @@ package providers
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
-	"os"
-	"path/filepath"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
-type AirAsiaProvider struct{}
-
-func (a *AirAsiaProvider) Name() string { return "AirAsia" }
-
-func (a *AirAsiaProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
-	var success bool
-	const maxRetries = 3
-	var lastErr error
+type AirAsiaProvider struct {
+	Config HTTPConfig
+}
 
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(time.Duration(rand.Intn(100)+50) * time.Millisecond)
-		if rand.Float32() < 0.9 {
-			success = true
-			break
-		}
-		lastErr = fmt.Errorf("AirAsia: failed attempt %d", i+1)
+func NewAirAsiaProvider(cfg HTTPConfig) *AirAsiaProvider {
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = "X-Api-Key"
 	}
+	return &AirAsiaProvider{Config: cfg}
+}
+
+func (a *AirAsiaProvider) Name() string { return "AirAsia" }
 
-	if !success {
-		return nil, fmt.Errorf("AirAsia timed out after %d retries: %w", maxRetries, lastErr)
+func (a *AirAsiaProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	query := url.Values{}
+	query.Set("from_airport", c.Origin)
+	query.Set("to_airport", c.Destination)
+	query.Set("depart_date", c.DepartureDate)
+	if c.Passengers > 0 {
+		query.Set("pax", strconv.Itoa(c.Passengers))
 	}
 
-	file := filepath.Join("mock", "airasia_search_response.json")
-	rawData, err := os.ReadFile(file)
+	rawData, err := a.Config.get(ctx, a.Name(), "/airasia/v2/flights", query)
 	if err != nil {
 		return nil, err
 	}
@@ -80,8 +79,12 @@ func (a *AirAsiaProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFligh
 		dur := CalculateDuration(depTime, arrTime)
 
 		stops := 0
+		var layovers []domain.Layover
 		if !f.Direct {
 			stops = len(f.StopsData)
+			for _, s := range f.StopsData {
+				layovers = append(layovers, newLayover(ctx, s.AirPort, s.WaitTime))
+			}
 		}
 
 		baggageInfo := domain.BaggageInfo{}
@@ -103,20 +106,9 @@ func (a *AirAsiaProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFligh
 			},
 			FlightNumber: f.Code,
 			Stops:        stops,
-			Departure: domain.FlightPoint{
-				Airport:   f.From,
-				City:      "Jakarta",
-				Datetime:  f.Dep,
-				Timestamp: depTime.Unix(),
-				TimeOfDay: depTime,
-			},
-			Arrival: domain.FlightPoint{
-				Airport:   f.To,
-				City:      "Denpasar",
-				Datetime:  f.Arr,
-				Timestamp: arrTime.Unix(),
-				TimeOfDay: arrTime,
-			},
+			Layovers:     layovers,
+			Departure:    newFlightPoint(ctx, f.From, "", depTime),
+			Arrival:      newFlightPoint(ctx, f.To, "", arrTime),
 			Duration: domain.DurationInfo{
 				TotalMinutes: dur,
 				Formatted:    fmt.Sprintf("%dh %dm", dur/60, dur%60),
@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/airports"
+	"context"
+	"fmt"
+	"time"
+)
+
+func lookupAirport(ctx context.Context, code string) (airports.Airport, bool) {
+	a, ok := airports.Lookup(code)
+	if !ok {
+		AddWarning(ctx, fmt.Sprintf("unknown airport code %q", code))
+	}
+	return a, ok
+}
+
+// airportLocation memilih timezone dari registry bandara. Jika bandara tidak dikenal,
+// timezone dari provider (jika ada) dipakai, lalu WIB sebagai pilihan terakhir.
+func airportLocation(ctx context.Context, code, providerTimezone string) *time.Location {
+	if a, ok := lookupAirport(ctx, code); ok {
+		return a.Location()
+	}
+	if providerTimezone != "" {
+		if loc, err := time.LoadLocation(providerTimezone); err == nil {
+			return loc
+		}
+	}
+	return LocationWIB
+}
+
+func newFlightPoint(ctx context.Context, code, providerCity string, t time.Time) domain.FlightPoint {
+	city := providerCity
+	if a, ok := lookupAirport(ctx, code); ok {
+		city = a.City
+		t = t.In(a.Location())
+	}
+
+	return domain.FlightPoint{
+		Airport:   code,
+		City:      city,
+		Datetime:  t.Format(time.RFC3339),
+		Timestamp: t.Unix(),
+		TimeOfDay: t,
+	}
+}
+
+func newLayover(ctx context.Context, code string, minutes int) domain.Layover {
+	l := domain.Layover{Airport: code, DurationMinutes: minutes}
+	if a, ok := lookupAirport(ctx, code); ok {
+		l.City = a.City
+	}
+	return l
+}
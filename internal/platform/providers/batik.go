@@ -2,24 +2,42 @@ package providers
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 )
 
-type BatikAirProvider struct{}
+type BatikAirProvider struct {
+	Config HTTPConfig
+}
+
+func NewBatikAirProvider(cfg HTTPConfig) *BatikAirProvider {
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = "X-Batik-Key"
+	}
+	return &BatikAirProvider{Config: cfg}
+}
 
 func (b *BatikAirProvider) Name() string { return "Batik Air" }
 
-func (b *BatikAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
-	time.Sleep(time.Duration(rand.Intn(200)+200) * time.Millisecond)
+func (b *BatikAirProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	type BatikSearchRequest struct {
+		Origin        string `json:"originAirport"`
+		Destination   string `json:"destinationAirport"`
+		DepartureDate string `json:"departureDate"`
+		Adults        int    `json:"adults"`
+		Cabin         string `json:"cabin,omitempty"`
+	}
 
-	file := filepath.Join("mock", "batik_air_search_response.json")
-	rawData, err := os.ReadFile(file)
+	rawData, err := b.Config.postJSON(ctx, b.Name(), "/batik/api/v1/search", BatikSearchRequest{
+		Origin:        c.Origin,
+		Destination:   c.Destination,
+		DepartureDate: c.DepartureDate,
+		Adults:        c.Passengers,
+		Cabin:         c.CabinClass,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +52,11 @@ func (b *BatikAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlig
 		Arr        string `json:"arrivalDateTime"`
 		TravelTime string `json:"travelTime"`
 		Stops      int    `json:"numberOfStops"`
-		Fare       struct {
+		Connection []struct {
+			Airport  string `json:"stopAirport"`
+			Duration string `json:"stopDuration"`
+		} `json:"connections"`
+		Fare struct {
 			BasePrice float64 `json:"basePrice"`
 			Taxes     float64 `json:"taxes"`
 			Total     float64 `json:"totalPrice"`
@@ -70,6 +92,17 @@ func (b *BatikAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlig
 
 		dur := CalculateDuration(depTime, arrTime)
 
+		var layovers []domain.Layover
+		for _, c := range f.Connection {
+			// Durasi yang tidak bisa dibaca dilewati agar tidak dianggap transit 0 menit oleh filter layover.
+			wait, err := time.ParseDuration(strings.ReplaceAll(c.Duration, " ", ""))
+			if err != nil {
+				AddWarning(ctx, fmt.Sprintf("flight %s: unreadable layover duration %q at %s", f.Num, c.Duration, c.Airport))
+				continue
+			}
+			layovers = append(layovers, newLayover(ctx, c.Airport, int(wait.Minutes())))
+		}
+
 		baggageParts := strings.Split(f.BaggageInfo, ",")
 		carryOn, checked := "", ""
 		if len(baggageParts) >= 1 {
@@ -89,20 +122,9 @@ func (b *BatikAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlig
 			},
 			FlightNumber: f.Num,
 			Stops:        f.Stops,
-			Departure: domain.FlightPoint{
-				Airport:   f.Org,
-				City:      "Jakarta",
-				Datetime:  depTime.Format(time.RFC3339),
-				Timestamp: depTime.Unix(),
-				TimeOfDay: depTime,
-			},
-			Arrival: domain.FlightPoint{
-				Airport:   f.Dst,
-				City:      "Denpasar",
-				Datetime:  arrTime.Format(time.RFC3339),
-				Timestamp: arrTime.Unix(),
-				TimeOfDay: arrTime,
-			},
+			Layovers:     layovers,
+			Departure:    newFlightPoint(ctx, f.Org, "", depTime),
+			Arrival:      newFlightPoint(ctx, f.Dst, "", arrTime),
 			Duration: domain.DurationInfo{
 				TotalMinutes: dur,
 				Formatted:    fmt.Sprintf("%dh %dm", dur/60, dur%60),
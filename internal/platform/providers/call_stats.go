@@ -0,0 +1,58 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// CallStats mengumpulkan informasi dari satu pemanggilan Search, dibawa lewat context
+// sehingga wrapper dan provider bisa melaporkannya tanpa mengubah ProviderInterface.
+type CallStats struct {
+	attempts atomic.Int32
+
+	mu       sync.Mutex
+	warnings []string
+	seen     map[string]struct{}
+}
+
+type callStatsKey struct{}
+
+func WithCallStats(ctx context.Context) (context.Context, *CallStats) {
+	stats := &CallStats{}
+	return context.WithValue(ctx, callStatsKey{}, stats), stats
+}
+
+func RecordAttempt(ctx context.Context) {
+	if stats, ok := ctx.Value(callStatsKey{}).(*CallStats); ok {
+		stats.attempts.Add(1)
+	}
+}
+
+func AddWarning(ctx context.Context, msg string) {
+	stats, ok := ctx.Value(callStatsKey{}).(*CallStats)
+	if !ok {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if stats.seen == nil {
+		stats.seen = map[string]struct{}{}
+	}
+	if _, dup := stats.seen[msg]; dup {
+		return
+	}
+	stats.seen[msg] = struct{}{}
+	stats.warnings = append(stats.warnings, msg)
+}
+
+func (s *CallStats) Attempts() int {
+	return int(s.attempts.Load())
+}
+
+func (s *CallStats) Warnings() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.warnings...)
+}
@@ -0,0 +1,238 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+type BreakerConfig struct {
+	// ConsecutiveFailures membuka circuit setelah N kegagalan berturut-turut.
+	ConsecutiveFailures int
+	// ErrorRateThreshold membuka circuit jika rasio error dalam Window >= threshold,
+	// setelah minimal MinRequests sampel terkumpul.
+	ErrorRateThreshold float64
+	MinRequests        int
+	Window             int
+	CoolDown           time.Duration
+	// HalfOpenSuccesses adalah jumlah probe sukses yang dibutuhkan untuk menutup circuit kembali.
+	HalfOpenSuccesses int
+}
+
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		ConsecutiveFailures: 5,
+		ErrorRateThreshold:  0.5,
+		MinRequests:         10,
+		Window:              20,
+		CoolDown:            15 * time.Second,
+		HalfOpenSuccesses:   2,
+	}
+}
+
+type BreakerSnapshot struct {
+	Provider            string     `json:"provider"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	ErrorRate           float64    `json:"error_rate"`
+	WindowSize          int        `json:"window_size"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	NextProbeAt         *time.Time `json:"next_probe_at,omitempty"`
+	TotalRequests       int64      `json:"total_requests"`
+	TotalFailures       int64      `json:"total_failures"`
+	TotalRejected       int64      `json:"total_rejected"`
+}
+
+// BreakerReporter diimplementasikan oleh provider yang dibungkus circuit breaker.
+type BreakerReporter interface {
+	BreakerSnapshot() BreakerSnapshot
+}
+
+type CircuitBreakerProvider struct {
+	Provider ProviderInterface
+	Config   BreakerConfig
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	window              []bool
+	windowPos           int
+	openedAt            time.Time
+	probeInFlight       bool
+	halfOpenSuccesses   int
+	totalRequests       int64
+	totalFailures       int64
+	totalRejected       int64
+}
+
+func WithCircuitBreaker(p ProviderInterface, cfg BreakerConfig) *CircuitBreakerProvider {
+	if cfg.Window < 1 {
+		cfg.Window = 1
+	}
+	if cfg.HalfOpenSuccesses < 1 {
+		cfg.HalfOpenSuccesses = 1
+	}
+	return &CircuitBreakerProvider{Provider: p, Config: cfg, state: BreakerClosed}
+}
+
+func (b *CircuitBreakerProvider) Name() string { return b.Provider.Name() }
+
+func (b *CircuitBreakerProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	probe, ok := b.allow()
+	if !ok {
+		return nil, ErrCircuitOpen
+	}
+
+	res, err := b.Provider.Search(ctx, c)
+
+	// Pembatalan dari client bukan kesalahan provider, jadi tidak dihitung.
+	if errors.Is(err, context.Canceled) {
+		if probe {
+			b.mu.Lock()
+			b.probeInFlight = false
+			b.mu.Unlock()
+		}
+		return res, err
+	}
+
+	b.record(probe, err == nil)
+	return res, err
+}
+
+func (b *CircuitBreakerProvider) allow() (probe bool, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.Config.CoolDown {
+		b.state = BreakerHalfOpen
+		b.halfOpenSuccesses = 0
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		b.totalRejected++
+		return false, false
+	case BreakerHalfOpen:
+		if b.probeInFlight {
+			b.totalRejected++
+			return false, false
+		}
+		b.probeInFlight = true
+		b.totalRequests++
+		return true, true
+	default:
+		b.totalRequests++
+		return false, true
+	}
+}
+
+func (b *CircuitBreakerProvider) record(probe, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !success {
+		b.totalFailures++
+	}
+
+	if probe {
+		b.probeInFlight = false
+		if !success {
+			b.trip()
+			return
+		}
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.Config.HalfOpenSuccesses {
+			b.reset()
+		}
+		return
+	}
+
+	if b.state != BreakerClosed {
+		return
+	}
+
+	b.pushOutcome(success)
+	if success {
+		b.consecutiveFailures = 0
+		return
+	}
+
+	b.consecutiveFailures++
+	if b.Config.ConsecutiveFailures > 0 && b.consecutiveFailures >= b.Config.ConsecutiveFailures {
+		b.trip()
+		return
+	}
+	if samples, rate := b.errorRate(); b.Config.ErrorRateThreshold > 0 && samples >= b.Config.MinRequests && rate >= b.Config.ErrorRateThreshold {
+		b.trip()
+	}
+}
+
+func (b *CircuitBreakerProvider) pushOutcome(success bool) {
+	if len(b.window) < b.Config.Window {
+		b.window = append(b.window, success)
+		return
+	}
+	b.window[b.windowPos] = success
+	b.windowPos = (b.windowPos + 1) % b.Config.Window
+}
+
+func (b *CircuitBreakerProvider) errorRate() (int, float64) {
+	if len(b.window) == 0 {
+		return 0, 0
+	}
+	failures := 0
+	for _, ok := range b.window {
+		if !ok {
+			failures++
+		}
+	}
+	return len(b.window), float64(failures) / float64(len(b.window))
+}
+
+func (b *CircuitBreakerProvider) trip() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.halfOpenSuccesses = 0
+}
+
+func (b *CircuitBreakerProvider) reset() {
+	b.state = BreakerClosed
+	b.consecutiveFailures = 0
+	b.window = b.window[:0]
+	b.windowPos = 0
+	b.halfOpenSuccesses = 0
+}
+
+func (b *CircuitBreakerProvider) BreakerSnapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, rate := b.errorRate()
+	snap := BreakerSnapshot{
+		Provider:            b.Name(),
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		ErrorRate:           rate,
+		WindowSize:          len(b.window),
+		TotalRequests:       b.totalRequests,
+		TotalFailures:       b.totalFailures,
+		TotalRejected:       b.totalRejected,
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		nextProbe := openedAt.Add(b.Config.CoolDown)
+		snap.OpenedAt = &openedAt
+		snap.NextProbeAt = &nextProbe
+	}
+	return snap
+}
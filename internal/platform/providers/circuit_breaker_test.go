@@ -0,0 +1,197 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errUpstream = errors.New("upstream unavailable")
+
+func testBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		ConsecutiveFailures: 3,
+		Window:              10,
+		CoolDown:            20 * time.Millisecond,
+		HalfOpenSuccesses:   2,
+	}
+}
+
+func search(b *CircuitBreakerProvider) error {
+	_, err := b.Search(context.Background(), domain.SearchCriteria{})
+	return err
+}
+
+func tripBreaker(t *testing.T, b *CircuitBreakerProvider) {
+	t.Helper()
+	for b.BreakerSnapshot().State != BreakerOpen {
+		if err := search(b); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("breaker rejected a call while closed")
+		}
+	}
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	provider := failing(errUpstream, func(int) bool { return true })
+	b := WithCircuitBreaker(provider, testBreakerConfig())
+
+	for i := range 3 {
+		if state := b.BreakerSnapshot().State; state != BreakerClosed {
+			t.Fatalf("state after %d failures = %s, want closed", i, state)
+		}
+		search(b)
+	}
+	if state := b.BreakerSnapshot().State; state != BreakerOpen {
+		t.Fatalf("state = %s, want open", state)
+	}
+
+	if err := search(b); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+	if provider.calls.Load() != 3 {
+		t.Errorf("provider called %d times, want 3 (open breaker must skip it)", provider.calls.Load())
+	}
+	if snap := b.BreakerSnapshot(); snap.TotalRejected != 1 || snap.NextProbeAt == nil {
+		t.Errorf("snapshot = %+v, want 1 rejection and a next probe time", snap)
+	}
+}
+
+func TestBreakerOpensOnErrorRate(t *testing.T) {
+	cfg := testBreakerConfig()
+	cfg.ConsecutiveFailures = 0
+	cfg.ErrorRateThreshold = 0.5
+	cfg.MinRequests = 4
+	cfg.Window = 4
+
+	// Gagal di panggilan genap: tidak pernah dua kali berturut-turut, tetapi rasio error 50%.
+	b := WithCircuitBreaker(failing(errUpstream, func(call int) bool { return call%2 == 0 }), cfg)
+
+	for i := range 3 {
+		search(b)
+		if state := b.BreakerSnapshot().State; state != BreakerClosed {
+			t.Fatalf("state after %d calls = %s, want closed before MinRequests", i+1, state)
+		}
+	}
+	search(b)
+	if snap := b.BreakerSnapshot(); snap.State != BreakerOpen || snap.ErrorRate != 0.5 {
+		t.Fatalf("snapshot = %+v, want open at error rate 0.5", snap)
+	}
+}
+
+func TestBreakerHalfOpenProbesCloseAfterCoolDown(t *testing.T) {
+	fail := true
+	b := WithCircuitBreaker(failing(errUpstream, func(int) bool { return fail }), testBreakerConfig())
+	tripBreaker(t, b)
+
+	fail = false
+	if err := search(b); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err before cool-down = %v, want ErrCircuitOpen", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := search(b); err != nil {
+		t.Fatalf("first probe err = %v", err)
+	}
+	if state := b.BreakerSnapshot().State; state != BreakerHalfOpen {
+		t.Fatalf("state after one successful probe = %s, want half_open", state)
+	}
+	if err := search(b); err != nil {
+		t.Fatalf("second probe err = %v", err)
+	}
+	if snap := b.BreakerSnapshot(); snap.State != BreakerClosed || snap.ConsecutiveFailures != 0 || snap.WindowSize != 0 {
+		t.Errorf("snapshot = %+v, want a reset closed breaker", snap)
+	}
+}
+
+func TestBreakerAllowsOneProbeAtATime(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	provider := &fakeProvider{search: func(ctx context.Context, call int) ([]domain.UnifiedFlight, error) {
+		if call <= 3 {
+			return nil, errUpstream
+		}
+		close(started)
+		<-release
+		return nil, nil
+	}}
+	b := WithCircuitBreaker(provider, testBreakerConfig())
+	tripBreaker(t, b)
+	time.Sleep(30 * time.Millisecond)
+
+	probeDone := make(chan error)
+	go func() { probeDone <- search(b) }()
+	<-started
+
+	if err := search(b); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("concurrent call during probe err = %v, want ErrCircuitOpen", err)
+	}
+	close(release)
+	if err := <-probeDone; err != nil {
+		t.Errorf("probe err = %v", err)
+	}
+	if provider.calls.Load() != 4 {
+		t.Errorf("provider called %d times, want 4", provider.calls.Load())
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	b := WithCircuitBreaker(failing(errUpstream, func(int) bool { return true }), testBreakerConfig())
+	tripBreaker(t, b)
+	firstOpened := *b.BreakerSnapshot().OpenedAt
+
+	time.Sleep(30 * time.Millisecond)
+	if err := search(b); !errors.Is(err, errUpstream) {
+		t.Fatalf("probe err = %v, want the upstream error", err)
+	}
+
+	snap := b.BreakerSnapshot()
+	if snap.State != BreakerOpen || !snap.OpenedAt.After(firstOpened) {
+		t.Fatalf("snapshot = %+v, want re-opened with a new cool-down", snap)
+	}
+	if err := search(b); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err after failed probe = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBreakerIgnoresCancellation(t *testing.T) {
+	cfg := testBreakerConfig()
+	cfg.ConsecutiveFailures = 1
+	b := WithCircuitBreaker(failing(context.Canceled, func(int) bool { return true }), cfg)
+
+	for range 5 {
+		if err := search(b); !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", err)
+		}
+	}
+	if snap := b.BreakerSnapshot(); snap.State != BreakerClosed || snap.TotalFailures != 0 || snap.WindowSize != 0 {
+		t.Errorf("snapshot = %+v, want cancellations not counted", snap)
+	}
+}
+
+func TestBreakerCancelledProbeReleasesSlot(t *testing.T) {
+	cancelled := true
+	provider := &fakeProvider{search: func(ctx context.Context, call int) ([]domain.UnifiedFlight, error) {
+		if call <= 3 {
+			return nil, errUpstream
+		}
+		if cancelled {
+			return nil, context.Canceled
+		}
+		return nil, nil
+	}}
+	b := WithCircuitBreaker(provider, testBreakerConfig())
+	tripBreaker(t, b)
+	time.Sleep(30 * time.Millisecond)
+
+	search(b)
+	if state := b.BreakerSnapshot().State; state != BreakerHalfOpen {
+		t.Fatalf("state after cancelled probe = %s, want half_open", state)
+	}
+
+	cancelled = false
+	if err := search(b); err != nil {
+		t.Errorf("next probe err = %v, want it to be allowed", err)
+	}
+}
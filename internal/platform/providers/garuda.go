@@ -2,23 +2,41 @@ package providers
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
-	"os"
-	"path/filepath"
 	"time"
 )
 
-type GarudaProvider struct{}
+type GarudaProvider struct {
+	Config HTTPConfig
+}
+
+func NewGarudaProvider(cfg HTTPConfig) *GarudaProvider {
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = "X-Api-Key"
+	}
+	return &GarudaProvider{Config: cfg}
+}
 
 func (g *GarudaProvider) Name() string { return "Garuda Indonesia" }
 
-func (g *GarudaProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
-	time.Sleep(time.Duration(rand.Intn(50)+50) * time.Millisecond)
+func (g *GarudaProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	type GarudaSearchRequest struct {
+		Origin        string `json:"origin"`
+		Destination   string `json:"destination"`
+		DepartureDate string `json:"departure_date"`
+		Passengers    int    `json:"passengers"`
+		CabinClass    string `json:"cabin_class,omitempty"`
+	}
 
-	file := filepath.Join("mock", "garuda_indonesia_search_response.json")
-	rawData, err := os.ReadFile(file)
+	rawData, err := g.Config.postJSON(ctx, g.Name(), "/garuda/v1/flights/search", GarudaSearchRequest{
+		Origin:        c.Origin,
+		Destination:   c.Destination,
+		DepartureDate: c.DepartureDate,
+		Passengers:    c.Passengers,
+		CabinClass:    c.CabinClass,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -35,8 +53,11 @@ func (g *GarudaProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlight
 		} `json:"price"`
 		Seats    int `json:"available_seats"`
 		Segments []struct {
-			Dep struct{ Time string } `json:"departure"`
-			Arr struct{ Time string } `json:"arrival"`
+			FlightNumber string                         `json:"flight_number"`
+			Dep          struct{ Airport, Time string } `json:"departure"`
+			Arr          struct{ Airport, Time string } `json:"arrival"`
+			Duration     int                            `json:"duration_minutes"`
+			Layover      int                            `json:"layover_minutes"`
 		} `json:"segments"`
 		Aircraft  string   `json:"aircraft"`
 		Amenities []string `json:"amenities"`
@@ -59,9 +80,48 @@ func (g *GarudaProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlight
 		depTime, errDep := time.Parse(time.RFC3339, f.Dep.Time)
 		arrTime, errArr := time.Parse(time.RFC3339, f.Arr.Time)
 
+		var segments []domain.Segment
+		var layovers []domain.Layover
+
 		if f.Stops > 0 && len(f.Segments) >= 2 {
 			depTime, _ = time.Parse(time.RFC3339, f.Segments[0].Dep.Time)
 			arrTime, _ = time.Parse(time.RFC3339, f.Segments[len(f.Segments)-1].Arr.Time)
+
+			var prevArr time.Time
+			for i, s := range f.Segments {
+				segDep, errSegDep := time.Parse(time.RFC3339, s.Dep.Time)
+				segArr, errSegArr := time.Parse(time.RFC3339, s.Arr.Time)
+				if errSegDep != nil || errSegArr != nil {
+					AddWarning(ctx, fmt.Sprintf("flight %s: unreadable time on segment %s", f.ID, s.FlightNumber))
+					// Transit sesudah segmen ini tidak dihitung dari kedatangan segmen sebelumnya.
+					prevArr = time.Time{}
+					continue
+				}
+
+				segDur := CalculateDuration(segDep, segArr)
+				segments = append(segments, domain.Segment{
+					FlightNumber:     s.FlightNumber,
+					OperatingCarrier: carrierFromFlightNumber(s.FlightNumber, f.Code),
+					Departure:        newFlightPoint(ctx, s.Dep.Airport, "", segDep),
+					Arrival:          newFlightPoint(ctx, s.Arr.Airport, "", segArr),
+					Duration: domain.DurationInfo{
+						TotalMinutes: segDur,
+						Formatted:    fmt.Sprintf("%dh %dm", segDur/60, segDur%60),
+					},
+					Aircraft: f.Aircraft,
+				})
+
+				// Tanpa durasi dari provider dan tanpa kedatangan segmen sebelumnya, transit dilewati
+				// agar tidak dianggap 0 menit oleh filter layover.
+				if i > 0 && (s.Layover != 0 || !prevArr.IsZero()) {
+					wait := s.Layover
+					if wait == 0 {
+						wait = CalculateDuration(prevArr, segDep)
+					}
+					layovers = append(layovers, newLayover(ctx, s.Dep.Airport, wait))
+				}
+				prevArr = segArr
+			}
 		}
 
 		if errDep != nil || errArr != nil {
@@ -82,20 +142,10 @@ func (g *GarudaProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlight
 			},
 			FlightNumber: f.ID,
 			Stops:        f.Stops,
-			Departure: domain.FlightPoint{
-				Airport:   f.Dep.Airport,
-				City:      f.Dep.City,
-				Datetime:  depTime.Format(time.RFC3339),
-				Timestamp: depTime.Unix(),
-				TimeOfDay: depTime,
-			},
-			Arrival: domain.FlightPoint{
-				Airport:   f.Arr.Airport,
-				City:      f.Arr.City,
-				Datetime:  arrTime.Format(time.RFC3339),
-				Timestamp: arrTime.Unix(),
-				TimeOfDay: arrTime,
-			},
+			Segments:     segments,
+			Layovers:     layovers,
+			Departure:    newFlightPoint(ctx, f.Dep.Airport, f.Dep.City, depTime),
+			Arrival:      newFlightPoint(ctx, f.Arr.Airport, f.Arr.City, arrTime),
 			Duration: domain.DurationInfo{
 				TotalMinutes: dur,
 				Formatted:    fmt.Sprintf("%dh %dm", dur/60, dur%60),
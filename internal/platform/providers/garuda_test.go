@@ -0,0 +1,47 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const garudaBrokenSegmentPayload = `{"flights":[{
+	"flight_id":"GA410","airline":"Garuda Indonesia","airline_code":"GA",
+	"departure":{"airport":"CGK","city":"Jakarta","time":"2025-12-15T06:00:00+07:00"},
+	"arrival":{"airport":"DPS","city":"Denpasar","time":"2025-12-15T14:00:00+08:00"},
+	"stops":2,"price":{"amount":1500000,"currency":"IDR"},"available_seats":9,
+	"segments":[
+		{"flight_number":"GA410","departure":{"airport":"CGK","time":"2025-12-15T06:00:00+07:00"},"arrival":{"airport":"SUB","time":"2025-12-15T07:30:00+07:00"}},
+		{"flight_number":"GA411","departure":{"airport":"SUB","time":"not-a-time"},"arrival":{"airport":"UPG","time":"2025-12-15T11:00:00+08:00"}},
+		{"flight_number":"GA412","departure":{"airport":"UPG","time":"2025-12-15T12:00:00+08:00"},"arrival":{"airport":"DPS","time":"2025-12-15T14:00:00+08:00"}}
+	]
+}]}`
+
+func TestGarudaSkipsLayoverAcrossUnreadableSegment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(garudaBrokenSegmentPayload))
+	}))
+	defer srv.Close()
+
+	ctx, stats := WithCallStats(context.Background())
+	flights, err := NewGarudaProvider(HTTPConfig{BaseURL: srv.URL}).Search(ctx, domain.SearchCriteria{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flights) != 1 {
+		t.Fatalf("got %d flights, want 1", len(flights))
+	}
+
+	// Segmen GA411 dilewati; transit di UPG tidak boleh dihitung dari kedatangan GA410 di SUB.
+	f := flights[0]
+	if len(f.Segments) != 2 || len(f.Layovers) != 0 {
+		t.Errorf("got %d segments and layovers %+v, want 2 segments and no layovers", len(f.Segments), f.Layovers)
+	}
+	if w := stats.Warnings(); len(w) != 1 || !strings.Contains(w[0], "GA411") {
+		t.Errorf("warnings = %v, want one for GA411", w)
+	}
+}
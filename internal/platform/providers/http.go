@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultHTTPTimeout = 2 * time.Second
+	maxResponseBytes   = 10 << 20
+)
+
+// HTTPConfig adalah konfigurasi koneksi ke API maskapai.
+type HTTPConfig struct {
+	BaseURL    string
+	AuthHeader string
+	AuthToken  string
+	Timeout    time.Duration
+	Client     *http.Client
+}
+
+// HTTPStatusError dikembalikan ketika API maskapai membalas dengan status non-2xx.
+type HTTPStatusError struct {
+	Provider   string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected HTTP status %d", e.Provider, e.StatusCode)
+}
+
+func (c HTTPConfig) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+func (c HTTPConfig) get(ctx context.Context, provider, path string, query url.Values) ([]byte, error) {
+	endpoint := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(provider, req)
+}
+
+func (c HTTPConfig) postJSON(ctx context.Context, provider, path string, body any) ([]byte, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := strings.TrimRight(c.BaseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(provider, req)
+}
+
+func (c HTTPConfig) do(provider string, req *http.Request) ([]byte, error) {
+	req.Header.Set("Accept", "application/json")
+	if c.AuthHeader != "" && c.AuthToken != "" {
+		req.Header.Set(c.AuthHeader, c.AuthToken)
+	}
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return nil, &HTTPStatusError{Provider: provider, StatusCode: resp.StatusCode}
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"context"
 	"time"
 
 	"golang.org/x/text/language"
@@ -17,7 +18,7 @@ var (
 )
 
 type ProviderInterface interface {
-	Search(criteria domain.SearchCriteria) ([]domain.UnifiedFlight, error)
+	Search(ctx context.Context, criteria domain.SearchCriteria) ([]domain.UnifiedFlight, error)
 	Name() string
 }
 
@@ -25,7 +26,28 @@ func CalculateDuration(start, end time.Time) int {
 	return int(end.Sub(start).Minutes())
 }
 
+// carrierFromFlightNumber mengambil kode maskapai (2 karakter IATA) dari nomor penerbangan.
+func carrierFromFlightNumber(flightNumber, fallback string) string {
+	if len(flightNumber) > 2 {
+		return flightNumber[:2]
+	}
+	return fallback
+}
+
 func FormatIDR(v float64) string {
 	amount := int64(v)
 	return p.Sprintf("Rp%d", amount)
 }
+
+// sleepContext menunggu selama d atau sampai ctx dibatalkan, mana yang lebih dulu.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
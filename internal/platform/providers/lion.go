@@ -2,23 +2,37 @@ package providers
 
 import (
 	"bookcabin-test/internal/core/domain"
+	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
-	"os"
-	"path/filepath"
+	"net/url"
+	"strconv"
 	"time"
 )
 
-type LionAirProvider struct{}
+type LionAirProvider struct {
+	Config HTTPConfig
+}
+
+func NewLionAirProvider(cfg HTTPConfig) *LionAirProvider {
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = "Authorization"
+	}
+	return &LionAirProvider{Config: cfg}
+}
 
 func (l *LionAirProvider) Name() string { return "Lion Air" }
 
-func (l *LionAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
-	time.Sleep(time.Duration(rand.Intn(100)+100) * time.Millisecond)
+func (l *LionAirProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	query := url.Values{}
+	query.Set("from", c.Origin)
+	query.Set("to", c.Destination)
+	query.Set("date", c.DepartureDate)
+	if c.Passengers > 0 {
+		query.Set("pax", strconv.Itoa(c.Passengers))
+	}
 
-	file := filepath.Join("mock", "lion_air_search_response.json")
-	rawData, err := os.ReadFile(file)
+	rawData, err := l.Config.get(ctx, l.Name(), "/lion/v1/flights/available", query)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +59,11 @@ func (l *LionAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFligh
 		StopCount  int  `json:"stop_count"`
 		FlightTime int  `json:"flight_time"`
 		IsDirect   bool `json:"is_direct"`
-		Pricing    struct {
+		Layovers   []struct {
+			Airport  string `json:"airport"`
+			Duration int    `json:"duration_minutes"`
+		} `json:"layovers"`
+		Pricing struct {
 			Total    float64 `json:"total"`
 			Currency string  `json:"currency"`
 			FareType string  `json:"fare_type"`
@@ -75,14 +93,8 @@ func (l *LionAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFligh
 	var results []domain.UnifiedFlight
 	layout := "2006-01-02T15:04:05"
 	for _, f := range resp.Data.Flights {
-		depLoc := LocationWIB
-		if f.Schedule.DepartureTimezone == "Asia/Makassar" {
-			depLoc = LocationWITA
-		}
-		arrLoc := LocationWIB
-		if f.Schedule.ArrivalTimezone == "Asia/Makassar" {
-			arrLoc = LocationWITA
-		}
+		depLoc := airportLocation(ctx, f.Route.From.Code, f.Schedule.DepartureTimezone)
+		arrLoc := airportLocation(ctx, f.Route.To.Code, f.Schedule.ArrivalTimezone)
 
 		depTime, errDep := time.ParseInLocation(layout, f.Schedule.Departure, depLoc)
 		arrTime, errArr := time.ParseInLocation(layout, f.Schedule.Arrival, arrLoc)
@@ -96,6 +108,11 @@ func (l *LionAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFligh
 
 		dur := CalculateDuration(depTime, arrTime)
 
+		var layovers []domain.Layover
+		for _, layover := range f.Layovers {
+			layovers = append(layovers, newLayover(ctx, layover.Airport, layover.Duration))
+		}
+
 		results = append(results, domain.UnifiedFlight{
 			ID:       f.ID + "_JT",
 			Provider: "Lion Air",
@@ -106,20 +123,9 @@ func (l *LionAirProvider) Search(c domain.SearchCriteria) ([]domain.UnifiedFligh
 			},
 			FlightNumber: f.ID,
 			Stops:        f.StopCount,
-			Departure: domain.FlightPoint{
-				Airport:   f.Route.From.Code,
-				City:      f.Route.From.City,
-				Datetime:  depTime.Format(time.RFC3339),
-				Timestamp: depTime.Unix(),
-				TimeOfDay: depTime,
-			},
-			Arrival: domain.FlightPoint{
-				Airport:   f.Route.To.Code,
-				City:      f.Route.To.City,
-				Datetime:  arrTime.Format(time.RFC3339),
-				Timestamp: arrTime.Unix(),
-				TimeOfDay: depTime,
-			},
+			Layovers:     layovers,
+			Departure:    newFlightPoint(ctx, f.Route.From.Code, f.Route.From.City, depTime),
+			Arrival:      newFlightPoint(ctx, f.Route.To.Code, f.Route.To.City, arrTime),
 			Duration: domain.DurationInfo{
 				TotalMinutes: dur,
 				Formatted:    fmt.Sprintf("%dh %dm", dur/60, dur%60),
@@ -0,0 +1,29 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"sync/atomic"
+)
+
+// fakeProvider mengembalikan hasil dari search untuk setiap panggilan dan menghitung jumlahnya.
+type fakeProvider struct {
+	search func(ctx context.Context, call int) ([]domain.UnifiedFlight, error)
+	calls  atomic.Int32
+}
+
+func (p *fakeProvider) Name() string { return "Fake" }
+
+func (p *fakeProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	return p.search(ctx, int(p.calls.Add(1)))
+}
+
+// failing mengembalikan provider yang gagal dengan err pada panggilan yang fail(call)-nya true.
+func failing(err error, fail func(call int) bool) *fakeProvider {
+	return &fakeProvider{search: func(ctx context.Context, call int) ([]domain.UnifiedFlight, error) {
+		if fail(call) {
+			return nil, err
+		}
+		return []domain.UnifiedFlight{{ID: "OK"}}, nil
+	}}
+}
@@ -0,0 +1,123 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter adalah fraksi (0..1) dari delay yang diacak untuk menghindari retry serentak.
+	Jitter float64
+	// AttemptTimeout membatasi satu percobaan; 0 berarti hanya dibatasi deadline pemanggil.
+	AttemptTimeout time.Duration
+	Retryable      func(error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   20 * time.Millisecond,
+		MaxDelay:    200 * time.Millisecond,
+		Jitter:      0.5,
+		Retryable:   IsRetryable,
+	}
+}
+
+// RetryProvider membungkus ProviderInterface dengan retry dan exponential backoff.
+type RetryProvider struct {
+	Provider ProviderInterface
+	Policy   RetryPolicy
+}
+
+func WithRetry(p ProviderInterface, policy RetryPolicy) *RetryProvider {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryable
+	}
+	return &RetryProvider{Provider: p, Policy: policy}
+}
+
+func (r *RetryProvider) Name() string { return r.Provider.Name() }
+
+func (r *RetryProvider) Search(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	var lastErr error
+	made := 0
+
+	for attempt := 1; attempt <= r.Policy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := r.Policy.backoff(attempt - 1)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+				break
+			}
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		made++
+		RecordAttempt(ctx)
+		res, err := r.searchOnce(ctx, c)
+		if err == nil {
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		lastErr = err
+		if !r.Policy.Retryable(err) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%s failed after %d attempt(s): %w", r.Name(), made, lastErr)
+}
+
+func (r *RetryProvider) searchOnce(ctx context.Context, c domain.SearchCriteria) ([]domain.UnifiedFlight, error) {
+	if r.Policy.AttemptTimeout <= 0 {
+		return r.Provider.Search(ctx, c)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, r.Policy.AttemptTimeout)
+	defer cancel()
+	return r.Provider.Search(attemptCtx, c)
+}
+
+// backoff menghitung delay sebelum retry ke-n: BaseDelay * 2^(n-1), dibatasi MaxDelay, dengan jitter.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	delay := p.BaseDelay << (n - 1)
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// IsRetryable menganggap error jaringan, timeout per percobaan, 408, 429 dan 5xx sebagai sementara.
+func IsRetryable(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError ||
+			statusErr.StatusCode == http.StatusTooManyRequests ||
+			statusErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,146 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 20 * time.Millisecond, MaxDelay: 200 * time.Millisecond}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 20 * time.Millisecond},
+		{2, 40 * time.Millisecond},
+		{3, 80 * time.Millisecond},
+		{4, 160 * time.Millisecond},
+		{5, 200 * time.Millisecond},
+		{40, 200 * time.Millisecond},
+		{64, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := policy.backoff(tt.retry); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffJitterStaysWithinBounds(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 20 * time.Millisecond, MaxDelay: 200 * time.Millisecond, Jitter: 0.5}
+
+	for retry := 1; retry <= 6; retry++ {
+		ceiling := min(policy.BaseDelay<<(retry-1), policy.MaxDelay)
+		for range 100 {
+			got := policy.backoff(retry)
+			if got < ceiling/2 || got > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", retry, got, ceiling/2, ceiling)
+			}
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"500", &HTTPStatusError{StatusCode: 500}, true},
+		{"503", &HTTPStatusError{StatusCode: 503}, true},
+		{"429", &HTTPStatusError{StatusCode: 429}, true},
+		{"408", &HTTPStatusError{StatusCode: 408}, true},
+		{"400", &HTTPStatusError{StatusCode: 400}, false},
+		{"401", &HTTPStatusError{StatusCode: 401}, false},
+		{"404", &HTTPStatusError{StatusCode: 404}, false},
+		{"wrapped 502", fmt.Errorf("garuda: %w", &HTTPStatusError{StatusCode: 502}), true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"cancelled", context.Canceled, false},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"decode", errors.New("unexpected end of JSON input"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryProvider(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	unavailable := &HTTPStatusError{StatusCode: 503}
+
+	tests := []struct {
+		name      string
+		provider  *fakeProvider
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds first time", failing(unavailable, func(int) bool { return false }), 1, false},
+		{"recovers from transient errors", failing(unavailable, func(call int) bool { return call < 3 }), 3, false},
+		{"gives up after max attempts", failing(unavailable, func(int) bool { return true }), 3, true},
+		{"does not retry permanent errors", failing(&HTTPStatusError{StatusCode: 400}, func(int) bool { return true }), 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stats := WithCallStats(context.Background())
+			_, err := WithRetry(tt.provider, policy).Search(ctx, domain.SearchCriteria{})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := int(tt.provider.calls.Load()); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if stats.Attempts() != tt.wantCalls {
+				t.Errorf("recorded attempts = %d, want %d", stats.Attempts(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryProviderStopsBeforeCallerDeadline(t *testing.T) {
+	provider := failing(&HTTPStatusError{StatusCode: 503}, func(int) bool { return true })
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := WithRetry(provider, policy).Search(ctx, domain.SearchCriteria{})
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("retry waited %v, past the caller deadline", elapsed)
+	}
+	if provider.calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", provider.calls.Load())
+	}
+	if err == nil || !strings.Contains(err.Error(), "after 1 attempt(s)") {
+		t.Errorf("err = %v, want failure after 1 attempt", err)
+	}
+}
+
+func TestRetryProviderAttemptTimeout(t *testing.T) {
+	provider := &fakeProvider{search: func(ctx context.Context, call int) ([]domain.UnifiedFlight, error) {
+		if call == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return []domain.UnifiedFlight{{ID: "OK"}}, nil
+	}}
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, AttemptTimeout: 20 * time.Millisecond}
+
+	res, err := WithRetry(provider, policy).Search(context.Background(), domain.SearchCriteria{})
+	if err != nil || len(res) != 1 {
+		t.Fatalf("got %v, %v; want result from the second attempt", res, err)
+	}
+	if provider.calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", provider.calls.Load())
+	}
+}
@@ -0,0 +1,118 @@
+package providers
+
+import (
+	"bookcabin-test/internal/core/domain"
+	"bookcabin-test/internal/platform/stubserver"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// baselineFlight adalah field inti hasil provider lama yang membaca folder mock/ langsung,
+// disimpan di testdata/baseline_flights.json.
+type baselineFlight struct {
+	ID              string  `json:"id"`
+	Provider        string  `json:"provider"`
+	AirlineName     string  `json:"airline_name"`
+	AirlineCode     string  `json:"airline_code"`
+	FlightNumber    string  `json:"flight_number"`
+	Origin          string  `json:"origin"`
+	Destination     string  `json:"destination"`
+	Departure       int64   `json:"departure_timestamp"`
+	Arrival         int64   `json:"arrival_timestamp"`
+	DurationMinutes int     `json:"duration_minutes"`
+	Stops           int     `json:"stops"`
+	Seats           int     `json:"available_seats"`
+	Price           float64 `json:"price"`
+	Currency        string  `json:"currency"`
+	Cabin           string  `json:"cabin_class"`
+	Valid           bool    `json:"valid"`
+}
+
+func toBaseline(f domain.UnifiedFlight) baselineFlight {
+	return baselineFlight{
+		ID:              f.ID,
+		Provider:        f.Provider,
+		AirlineName:     f.Airline.Name,
+		AirlineCode:     f.Airline.Code,
+		FlightNumber:    f.FlightNumber,
+		Origin:          f.Departure.Airport,
+		Destination:     f.Arrival.Airport,
+		Departure:       f.Departure.Timestamp,
+		Arrival:         f.Arrival.Timestamp,
+		DurationMinutes: f.Duration.TotalMinutes,
+		Stops:           f.Stops,
+		Seats:           f.AvailableSeats,
+		Price:           f.Price.Amount,
+		Currency:        f.Price.Currency,
+		Cabin:           f.CabinClass,
+		Valid:           f.IsValid,
+	}
+}
+
+func TestHTTPProvidersMatchBaselineMockProviders(t *testing.T) {
+	raw, err := os.ReadFile("testdata/baseline_flights.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var baseline map[string][]baselineFlight
+	if err := json.Unmarshal(raw, &baseline); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := stubserver.DefaultConfig()
+	for i := range cfg.Routes {
+		cfg.Routes[i].MinLatency, cfg.Routes[i].MaxLatency, cfg.Routes[i].ErrorRate = 0, 0, 0
+	}
+	srv := httptest.NewServer(stubserver.NewHandler(cfg))
+	defer srv.Close()
+
+	httpCfg := HTTPConfig{BaseURL: srv.URL}
+	for _, p := range []ProviderInterface{
+		NewGarudaProvider(httpCfg),
+		NewLionAirProvider(httpCfg),
+		NewBatikAirProvider(httpCfg),
+		NewAirAsiaProvider(httpCfg),
+	} {
+		want := baseline[p.Name()]
+		if len(want) == 0 {
+			t.Errorf("%s: no baseline flights", p.Name())
+			continue
+		}
+
+		// Stub menyaring per rute, jadi setiap rute di baseline diminta terpisah dengan urutan yang sama.
+		var got []baselineFlight
+		requested := map[string]bool{}
+		for _, b := range want {
+			route := b.Origin + "-" + b.Destination
+			if requested[route] {
+				continue
+			}
+			requested[route] = true
+
+			flights, err := p.Search(context.Background(), domain.SearchCriteria{Origin: b.Origin, Destination: b.Destination, DepartureDate: "2025-12-15", Passengers: 1})
+			if err != nil {
+				t.Fatalf("%s %s: %v", p.Name(), route, err)
+			}
+			for _, f := range flights {
+				got = append(got, toBaseline(f))
+			}
+		}
+
+		if len(got) != len(want) {
+			t.Errorf("%s: got %d flights, want %d", p.Name(), len(got), len(want))
+			continue
+		}
+		byID := map[string]baselineFlight{}
+		for _, f := range got {
+			byID[f.ID] = f
+		}
+		for _, w := range want {
+			if g, ok := byID[w.ID]; !ok || g != w {
+				t.Errorf("%s: flight %s = %+v, want %+v", p.Name(), w.ID, g, w)
+			}
+		}
+	}
+}
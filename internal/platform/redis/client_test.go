@@ -0,0 +1,151 @@
+package redis_test
+
+import (
+	"bookcabin-test/internal/platform/redis"
+	"bookcabin-test/internal/platform/redis/redistest"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T) (*redis.Client, *redistest.Server) {
+	t.Helper()
+	srv, err := redistest.StartServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(redis.Config{Addr: srv.Addr(), PoolSize: 1})
+	t.Cleanup(func() {
+		client.Close()
+		srv.Close()
+	})
+	return client, srv
+}
+
+func TestClientGetSetDel(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if _, ok, err := client.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("get missing = ok %v, err %v; want a miss", ok, err)
+	}
+
+	value := []byte("line one\r\nline two with $ and *")
+	if err := client.Set(ctx, "k", value, time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	got, ok, err := client.Get(ctx, "k")
+	if err != nil || !ok || string(got) != string(value) {
+		t.Fatalf("get = %q, %v, %v; want %q", got, ok, err, value)
+	}
+
+	if err := client.Del(ctx, "k"); err != nil {
+		t.Fatalf("del: %v", err)
+	}
+	if _, ok, _ := client.Get(ctx, "k"); ok {
+		t.Error("key still present after DEL")
+	}
+}
+
+func TestClientTTLExpiry(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+
+	if err := client.Set(ctx, "short", []byte("v"), 20*time.Millisecond); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := client.Set(ctx, "forever", []byte("v"), 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, ok, _ := client.Get(ctx, "short"); !ok {
+		t.Fatal("key expired too early")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, ok, _ := client.Get(ctx, "short"); ok {
+		t.Error("key with PX ttl did not expire")
+	}
+	if _, ok, _ := client.Get(ctx, "forever"); !ok {
+		t.Error("key without ttl expired")
+	}
+}
+
+func TestClientReusesConnectionAfterServerError(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newTestClient(t)
+
+	if err := client.Set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	srv.FailNext("READONLY You can't write against a read only replica.")
+	err := client.Set(ctx, "k", []byte("v2"), 0)
+	var serverErr redis.ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("err = %v, want a ServerError", err)
+	}
+
+	got, ok, err := client.Get(ctx, "k")
+	if err != nil || !ok || string(got) != "v" {
+		t.Fatalf("get after server error = %q, %v, %v; want the old value", got, ok, err)
+	}
+	if n := srv.Accepted(); n != 1 {
+		t.Errorf("server accepted %d connections, want the pooled connection to be reused", n)
+	}
+}
+
+func TestClientRedialsAfterConnectionLoss(t *testing.T) {
+	ctx := context.Background()
+	srv, err := redistest.StartServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(redis.Config{Addr: srv.Addr(), PoolSize: 1})
+	defer client.Close()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	addr := srv.Addr()
+	srv.Close()
+
+	if err := client.Ping(ctx); err == nil {
+		t.Fatal("ping succeeded against a closed server")
+	}
+
+	srv, err = redistest.StartServer(addr)
+	if err != nil {
+		t.Skipf("could not rebind %s: %v", addr, err)
+	}
+	defer srv.Close()
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("ping after restart: %v, want a fresh connection", err)
+	}
+}
+
+func TestServerCloseShutsOpenConnections(t *testing.T) {
+	srv, err := redistest.StartServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(redis.Config{Addr: srv.Addr()})
+	defer client.Close()
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		srv.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return while a client connection was open")
+	}
+}
@@ -0,0 +1,223 @@
+// Package redistest menyediakan server RESP in-process untuk test dan development lokal,
+// sehingga client redis bisa diuji tanpa instance Redis sungguhan.
+package redistest
+
+import (
+	"bookcabin-test/internal/platform/redis"
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type fakeEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// Server adalah server RESP in-process yang mendukung GET, SET (dengan EX/PX), DEL, PING,
+// AUTH dan SELECT. Close menutup listener dan semua koneksi yang masih terbuka.
+type Server struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mu       sync.Mutex
+	data     map[string]fakeEntry
+	conns    map[net.Conn]struct{}
+	accepted int
+	failNext string
+	closed   bool
+}
+
+// StartServer menjalankan Server pada addr (mis. "127.0.0.1:0").
+func StartServer(addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{ln: ln, data: map[string]fakeEntry{}, conns: map[net.Conn]struct{}{}}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Accepted mengembalikan jumlah koneksi yang pernah diterima server.
+func (s *Server) Accepted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// FailNext membuat perintah berikutnya dibalas dengan error `-ERR msg`.
+func (s *Server) FailNext(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = msg
+}
+
+// Close menghentikan listener, menutup semua koneksi, dan menunggu semua goroutine selesai.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+
+	s.mu.Lock()
+	s.closed = true
+	for nc := range s.conns {
+		nc.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			nc.Close()
+			return
+		}
+		s.conns[nc] = struct{}{}
+		s.accepted++
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(nc)
+	}
+}
+
+func (s *Server) handle(nc net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, nc)
+		s.mu.Unlock()
+		nc.Close()
+	}()
+	r := bufio.NewReader(nc)
+	w := bufio.NewWriter(nc)
+
+	for {
+		req, err := redis.ReadReply(r)
+		if err != nil {
+			return
+		}
+		items, ok := req.([]any)
+		if !ok || len(items) == 0 {
+			w.WriteString("-ERR protocol error\r\n")
+		} else {
+			args := make([]string, len(items))
+			for i, item := range items {
+				b, _ := item.([]byte)
+				args[i] = string(b)
+			}
+			s.exec(w, args)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(w *bufio.Writer, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failNext != "" {
+		w.WriteString("-ERR " + s.failNext + "\r\n")
+		s.failNext = ""
+		return
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "AUTH", "SELECT":
+		w.WriteString("+OK\r\n")
+	case "GET":
+		if len(args) != 2 {
+			w.WriteString("-ERR wrong number of arguments for 'get' command\r\n")
+			return
+		}
+		entry, ok := s.lookup(args[1])
+		if !ok {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		w.WriteString("$" + strconv.Itoa(len(entry.value)) + "\r\n")
+		w.Write(entry.value)
+		w.WriteString("\r\n")
+	case "SET":
+		if len(args) < 3 {
+			w.WriteString("-ERR wrong number of arguments for 'set' command\r\n")
+			return
+		}
+		entry := fakeEntry{value: []byte(args[2])}
+		if len(args) > 3 {
+			ttl, err := parseExpiry(args[3:])
+			if err != nil {
+				w.WriteString("-ERR " + err.Error() + "\r\n")
+				return
+			}
+			entry.expiresAt = time.Now().Add(ttl)
+		}
+		s.data[args[1]] = entry
+		w.WriteString("+OK\r\n")
+	case "DEL":
+		var n int
+		for _, key := range args[1:] {
+			if _, ok := s.lookup(key); ok {
+				delete(s.data, key)
+				n++
+			}
+		}
+		w.WriteString(":" + strconv.Itoa(n) + "\r\n")
+	default:
+		w.WriteString("-ERR unknown command '" + args[0] + "'\r\n")
+	}
+}
+
+func (s *Server) lookup(key string) (fakeEntry, bool) {
+	entry, ok := s.data[key]
+	if !ok {
+		return fakeEntry{}, false
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(s.data, key)
+		return fakeEntry{}, false
+	}
+	return entry, true
+}
+
+func parseExpiry(args []string) (time.Duration, error) {
+	if len(args) != 2 {
+		return 0, errors.New("syntax error")
+	}
+	n, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid expire time in 'set' command")
+	}
+	switch strings.ToUpper(args[0]) {
+	case "PX":
+		return time.Duration(n) * time.Millisecond, nil
+	case "EX":
+		return time.Duration(n) * time.Second, nil
+	default:
+		return 0, errors.New("syntax error")
+	}
+}
@@ -0,0 +1,224 @@
+package stubserver
+
+import (
+	"bookcabin-test/mock"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Route adalah satu endpoint airline palsu yang melayani payload dari folder mock/.
+type Route struct {
+	Path       string
+	File       string
+	MinLatency time.Duration
+	MaxLatency time.Duration
+	ErrorRate  float64
+	AuthHeader string
+	AuthToken  string
+	// Query, jika diisi, membuat endpoint hanya mengembalikan penerbangan yang cocok dengan
+	// origin, destination dan tanggal yang diminta; tanpa Query seluruh payload dikembalikan.
+	Query *RouteQuery
+}
+
+// RouteQuery memetakan field request dan field penerbangan di payload maskapai. Path memakai
+// titik untuk objek bertingkat, mis. "data.available_flights" atau "route.from.code".
+type RouteQuery struct {
+	// Origin, Destination dan Date adalah nama query parameter (GET) atau field body JSON (POST).
+	Origin      string
+	Destination string
+	Date        string
+
+	Flights           string
+	FlightOrigin      string
+	FlightDestination string
+	// FlightDeparture adalah waktu keberangkatan lokal; 10 karakter pertamanya dibandingkan dengan Date.
+	FlightDeparture string
+}
+
+type Config struct {
+	Routes []Route
+}
+
+// DefaultConfig meniru karakteristik latensi dan error masing-masing maskapai.
+func DefaultConfig() Config {
+	return Config{Routes: []Route{
+		{
+			Path: "/garuda/v1/flights/search", File: "garuda_indonesia_search_response.json",
+			MinLatency: 50 * time.Millisecond, MaxLatency: 100 * time.Millisecond, AuthHeader: "X-Api-Key",
+			Query: &RouteQuery{
+				Origin: "origin", Destination: "destination", Date: "departure_date",
+				Flights: "flights", FlightOrigin: "departure.airport", FlightDestination: "arrival.airport", FlightDeparture: "departure.time",
+			},
+		},
+		{
+			Path: "/lion/v1/flights/available", File: "lion_air_search_response.json",
+			MinLatency: 100 * time.Millisecond, MaxLatency: 200 * time.Millisecond, AuthHeader: "Authorization",
+			Query: &RouteQuery{
+				Origin: "from", Destination: "to", Date: "date",
+				Flights: "data.available_flights", FlightOrigin: "route.from.code", FlightDestination: "route.to.code", FlightDeparture: "schedule.departure",
+			},
+		},
+		{
+			Path: "/batik/api/v1/search", File: "batik_air_search_response.json",
+			MinLatency: 200 * time.Millisecond, MaxLatency: 400 * time.Millisecond, AuthHeader: "X-Batik-Key",
+			Query: &RouteQuery{
+				Origin: "originAirport", Destination: "destinationAirport", Date: "departureDate",
+				Flights: "results", FlightOrigin: "origin", FlightDestination: "destination", FlightDeparture: "departureDateTime",
+			},
+		},
+		{
+			Path: "/airasia/v2/flights", File: "airasia_search_response.json",
+			MinLatency: 50 * time.Millisecond, MaxLatency: 150 * time.Millisecond, ErrorRate: 0.1, AuthHeader: "X-Api-Key",
+			Query: &RouteQuery{
+				Origin: "from_airport", Destination: "to_airport", Date: "depart_date",
+				Flights: "flights", FlightOrigin: "from_airport", FlightDestination: "to_airport", FlightDeparture: "depart_time",
+			},
+		},
+	}}
+}
+
+func NewHandler(cfg Config) http.Handler {
+	mux := http.NewServeMux()
+	for _, route := range cfg.Routes {
+		payload, err := mock.Files.ReadFile(route.File)
+		if err != nil {
+			log.Printf("stubserver: skipping %s: %v", route.Path, err)
+			continue
+		}
+		var doc map[string]any
+		if route.Query != nil {
+			decoder := json.NewDecoder(bytes.NewReader(payload))
+			decoder.UseNumber()
+			if err := decoder.Decode(&doc); err != nil {
+				log.Printf("stubserver: skipping %s: %v", route.Path, err)
+				continue
+			}
+		}
+		mux.HandleFunc(route.Path, routeHandler(route, payload, doc))
+	}
+	return mux
+}
+
+func routeHandler(route Route, payload []byte, doc map[string]any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if route.AuthToken != "" && r.Header.Get(route.AuthHeader) != route.AuthToken {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		latency := route.MinLatency
+		if route.MaxLatency > route.MinLatency {
+			latency += time.Duration(rand.Int63n(int64(route.MaxLatency - route.MinLatency)))
+		}
+
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+
+		if rand.Float64() < route.ErrorRate {
+			http.Error(w, `{"error":"service temporarily unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+
+		body := payload
+		if route.Query != nil {
+			params, err := requestParams(r)
+			if err != nil {
+				http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+				return
+			}
+			if body, err = filterPayload(doc, *route.Query, params); err != nil {
+				http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+// requestParams membaca parameter pencarian dari query string (GET) atau body JSON (POST).
+func requestParams(r *http.Request) (map[string]string, error) {
+	params := map[string]string{}
+	if r.Method != http.MethodPost {
+		for key := range r.URL.Query() {
+			params[key] = r.URL.Query().Get(key)
+		}
+		return params, nil
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	for key, v := range body {
+		params[key] = fmt.Sprint(v)
+	}
+	return params, nil
+}
+
+// filterPayload mengembalikan payload dengan hanya penerbangan yang cocok dengan rute dan tanggal
+// yang diminta. Parameter yang kosong tidak menyaring.
+func filterPayload(doc map[string]any, q RouteQuery, params map[string]string) ([]byte, error) {
+	flights, _ := lookup(doc, q.Flights).([]any)
+	matched := []any{}
+	for _, f := range flights {
+		if matches(f, q.FlightOrigin, params[q.Origin]) &&
+			matches(f, q.FlightDestination, params[q.Destination]) &&
+			matchesDate(f, q.FlightDeparture, params[q.Date]) {
+			matched = append(matched, f)
+		}
+	}
+	return json.Marshal(replace(doc, strings.Split(q.Flights, "."), matched))
+}
+
+func lookup(v any, path string) any {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+func matches(flight any, path, want string) bool {
+	if want == "" {
+		return true
+	}
+	got, _ := lookup(flight, path).(string)
+	return got == want
+}
+
+func matchesDate(flight any, path, date string) bool {
+	if date == "" {
+		return true
+	}
+	departure, _ := lookup(flight, path).(string)
+	return strings.HasPrefix(departure, date)
+}
+
+// replace menyalin objek di sepanjang path sehingga doc asli tidak diubah antar request.
+func replace(doc map[string]any, path []string, v any) map[string]any {
+	res := make(map[string]any, len(doc))
+	for key, val := range doc {
+		res[key] = val
+	}
+	if len(path) == 1 {
+		res[path[0]] = v
+		return res
+	}
+	child, _ := doc[path[0]].(map[string]any)
+	res[path[0]] = replace(child, path[1:], v)
+	return res
+}
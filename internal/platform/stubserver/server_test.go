@@ -0,0 +1,72 @@
+package stubserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// instantConfig adalah DefaultConfig tanpa latensi dan error acak.
+func instantConfig() Config {
+	cfg := DefaultConfig()
+	for i := range cfg.Routes {
+		cfg.Routes[i].MinLatency, cfg.Routes[i].MaxLatency, cfg.Routes[i].ErrorRate = 0, 0, 0
+	}
+	return cfg
+}
+
+func TestRoutesFilterByQuery(t *testing.T) {
+	h := NewHandler(instantConfig())
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		path string
+		want int
+	}{
+		{"garuda route", httptest.NewRequest(http.MethodPost, "/garuda/v1/flights/search", strings.NewReader(`{"origin":"CGK","destination":"DPS","departure_date":"2025-12-15"}`)), "flights", 2},
+		{"garuda other destination", httptest.NewRequest(http.MethodPost, "/garuda/v1/flights/search", strings.NewReader(`{"origin":"CGK","destination":"SUB","departure_date":"2025-12-15"}`)), "flights", 1},
+		{"garuda other date", httptest.NewRequest(http.MethodPost, "/garuda/v1/flights/search", strings.NewReader(`{"origin":"CGK","destination":"DPS","departure_date":"2025-12-16"}`)), "flights", 0},
+		{"lion route", httptest.NewRequest(http.MethodGet, "/lion/v1/flights/available?from=CGK&to=DPS&date=2025-12-15", nil), "data.available_flights", 3},
+		{"lion reversed route", httptest.NewRequest(http.MethodGet, "/lion/v1/flights/available?from=DPS&to=CGK&date=2025-12-15", nil), "data.available_flights", 0},
+		{"batik route", httptest.NewRequest(http.MethodPost, "/batik/api/v1/search", strings.NewReader(`{"originAirport":"CGK","destinationAirport":"DPS","departureDate":"2025-12-15","adults":1}`)), "results", 3},
+		{"airasia other origin", httptest.NewRequest(http.MethodGet, "/airasia/v2/flights?from_airport=HLP&to_airport=DPS&depart_date=2025-12-15", nil), "flights", 0},
+		{"airasia without query", httptest.NewRequest(http.MethodGet, "/airasia/v2/flights", nil), "flights", 4},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, tt.req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d", tt.name, rec.Code)
+			continue
+		}
+
+		var doc map[string]any
+		if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		flights, ok := lookup(doc, tt.path).([]any)
+		if !ok || len(flights) != tt.want {
+			t.Errorf("%s: got %d flights, want %d", tt.name, len(flights), tt.want)
+		}
+	}
+}
+
+func TestRouteWithoutQueryReturnsWholePayload(t *testing.T) {
+	h := NewHandler(Config{Routes: []Route{{Path: "/garuda", File: "garuda_indonesia_search_response.json"}}})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/garuda", strings.NewReader(`{"origin":"HLP"}`)))
+
+	var doc map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if flights, _ := doc["flights"].([]any); len(flights) != 3 {
+		t.Errorf("got %d flights, want the whole payload (3)", len(flights))
+	}
+}
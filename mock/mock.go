@@ -0,0 +1,8 @@
+package mock
+
+import "embed"
+
+// Files berisi payload contoh dari setiap maskapai, dipakai oleh stub airline server.
+//
+//go:embed *.json
+var Files embed.FS